@@ -0,0 +1,49 @@
+package logger
+
+import (
+	"context"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// AccessFields describes a served HTTP request for LogAccess.
+type AccessFields struct {
+	Method    string
+	Path      string
+	Status    int
+	Duration  time.Duration
+	Bytes     int64
+	RemoteIP  string
+	UserAgent string
+}
+
+// LogAccess logs an "access" entry at info level through FromCtx(ctx),
+// with the fields of f under the standard http.* keys.
+func LogAccess(ctx context.Context, f AccessFields) {
+	FromCtx(ctx).WithOptions(zap.AddCallerSkip(1)).Info("access",
+		zap.String("http.method", f.Method),
+		zap.String("http.path", f.Path),
+		zap.Int("http.status", f.Status),
+		zap.Float64("http.duration_ms", float64(f.Duration)/float64(time.Millisecond)),
+		zap.Int64("http.bytes", f.Bytes),
+		zap.String("http.remote_ip", f.RemoteIP),
+		zap.String("http.user_agent", f.UserAgent),
+	)
+}
+
+// RequestFields returns the fields describing an HTTP request, under the
+// same http.* keys as LogAccess.
+func RequestFields(method, path, remoteIP string) []zap.Field {
+	return []zap.Field{
+		zap.String("http.method", method),
+		zap.String("http.path", path),
+		zap.String("http.remote_ip", remoteIP),
+	}
+}
+
+// WithRequest returns a copy of ctx with a child of its Logger adding
+// RequestFields to every entry.
+func WithRequest(ctx context.Context, method, path, remoteIP string) context.Context {
+	return WithFields(ctx, RequestFields(method, path, remoteIP)...)
+}
@@ -0,0 +1,66 @@
+package logger
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestLogAccess(t *testing.T) {
+	_, buf := newTestLogger(t, "info", Config{})
+
+	LogAccess(context.Background(), AccessFields{
+		Method:    "GET",
+		Path:      "/orders",
+		Status:    200,
+		Duration:  1500 * time.Microsecond,
+		Bytes:     512,
+		RemoteIP:  "10.0.0.1",
+		UserAgent: "curl/8.0",
+	})
+
+	entry := decodeEntries(t, buf.String())[0]
+	want := map[string]interface{}{
+		"http.method":      "GET",
+		"http.path":        "/orders",
+		"http.status":      float64(200),
+		"http.duration_ms": 1.5,
+		"http.bytes":       float64(512),
+		"http.remote_ip":   "10.0.0.1",
+		"http.user_agent":  "curl/8.0",
+	}
+
+	for key, v := range want {
+		if entry[key] != v {
+			t.Errorf("%s = %#v, want %#v", key, entry[key], v)
+		}
+	}
+
+	if entry["msg"] != "access" {
+		t.Errorf("msg = %v, want access", entry["msg"])
+	}
+}
+
+func TestWithRequest(t *testing.T) {
+	l, buf := newTestLogger(t, "info", Config{})
+
+	ctx := WithRequest(WithCtx(context.Background(), l), "POST", "/orders", "10.0.0.2")
+	FromCtx(ctx).Info("handled")
+
+	entry := decodeEntries(t, buf.String())[0]
+	want := map[string]interface{}{
+		"http.method":    "POST",
+		"http.path":      "/orders",
+		"http.remote_ip": "10.0.0.2",
+	}
+
+	for key, v := range want {
+		if entry[key] != v {
+			t.Errorf("%s = %#v, want %#v", key, entry[key], v)
+		}
+	}
+
+	if n := len(RequestFields("GET", "/", "")); n != len(want) {
+		t.Errorf("RequestFields returned %d fields, want %d", n, len(want))
+	}
+}
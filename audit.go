@@ -0,0 +1,62 @@
+package logger
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+var auditOnce sync.Once
+
+var auditLogger *zap.Logger
+
+// auditFile is the rotating file written to by auditLogger.
+var auditFile fileSink
+
+// Audit initializes the audit logger if it has not been initialized
+// already and returns the same instance for subsequent calls. The audit
+// logger writes JSON at info level to its own log file at logPath. It is
+// independent of Get: it is never sampled, never writes to the console
+// and ignores SetLevel.
+func Audit(logPath string) *zap.Logger {
+	auditOnce.Do(func() {
+		_ = os.MkdirAll(filepath.Dir(logPath), 0o755)
+
+		auditFile = newFileSink(logPath, Config{})
+		auditLogger = zap.New(zapcore.NewCore(
+			newEncoder(Config{}, FormatJSON, false),
+			zapcore.AddSync(auditFile),
+			zap.InfoLevel,
+		))
+	})
+
+	return auditLogger
+}
+
+// AuditEvent records that the user stored in ctx performed action, with
+// the given fields, to the audit logger. It does nothing until Audit has
+// been called.
+func AuditEvent(ctx context.Context, action string, fields ...zap.Field) {
+	if auditLogger == nil {
+		return
+	}
+
+	auditLogger.Info("audit",
+		append([]zap.Field{zap.String("action", action), LogUserId(ctx)}, fields...)...,
+	)
+}
+
+// resetAudit closes and discards the audit logger.
+func resetAudit() {
+	if auditFile != nil {
+		_ = auditFile.Close()
+	}
+
+	auditOnce = sync.Once{}
+	auditLogger = nil
+	auditFile = nil
+}
@@ -0,0 +1,36 @@
+package logger
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"go.uber.org/zap"
+)
+
+func TestAudit(t *testing.T) {
+	newTestLogger(t, "error", Config{})
+
+	path := filepath.Join(t.TempDir(), "audit.log")
+	Audit(path)
+	SetLevel(zap.FatalLevel)
+
+	AuditEvent(WithUserID(context.Background(), "u1"), "delete", zap.String("resource", "doc-1"))
+	_ = Audit(path).Sync()
+
+	b, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	entries := decodeEntries(t, string(b))
+	if len(entries) != 1 {
+		t.Fatalf("got %d audit entries, want 1: %s", len(entries), strings.TrimSpace(string(b)))
+	}
+
+	if e := entries[0]; e["action"] != "delete" || e["user_id"] != "u1" || e["resource"] != "doc-1" {
+		t.Errorf("got %v", e)
+	}
+}
@@ -0,0 +1,125 @@
+package logger
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"gopkg.in/natefinch/lumberjack.v2"
+)
+
+// compressingSink is a rotating file handing every backup lumberjack
+// creates to compress, then deleting the backup.
+type compressingSink struct {
+	*lumberjack.Logger
+	compress func(path string) error
+
+	mu   sync.Mutex
+	size int64
+
+	// pending tracks the backups being compressed.
+	pending sync.WaitGroup
+	running sync.Mutex
+}
+
+// newCompressingSink returns l handing its backups to compress.
+func newCompressingSink(l *lumberjack.Logger, compress func(path string) error) *compressingSink {
+	s := &compressingSink{Logger: l, compress: compress}
+	if info, err := os.Stat(l.Filename); err == nil {
+		s.size = info.Size()
+	}
+
+	return s
+}
+
+func (s *compressingSink) Write(p []byte) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// lumberjack rotates before a write that would exceed MaxSize
+	rotates := s.size+int64(len(p)) > int64(s.MaxSize)*1024*1024
+
+	n, err := s.Logger.Write(p)
+	if rotates {
+		s.size = int64(n)
+		s.compressBackups()
+	} else {
+		s.size += int64(n)
+	}
+
+	return n, err
+}
+
+func (s *compressingSink) Rotate() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	err := s.Logger.Rotate()
+	s.size = 0
+	s.compressBackups()
+
+	return err
+}
+
+// Close closes the file once the pending backups are compressed.
+func (s *compressingSink) Close() error {
+	s.pending.Wait()
+	return s.Logger.Close()
+}
+
+// compressBackups compresses the uncompressed backups in the background.
+func (s *compressingSink) compressBackups() {
+	s.pending.Add(1)
+	go func() {
+		defer s.pending.Done()
+
+		s.running.Lock()
+		defer s.running.Unlock()
+
+		for _, backup := range s.backups() {
+			if err := s.compressBackup(backup); err != nil {
+				fmt.Fprintf(os.Stderr, "logger: failed to compress %s: %v\n", backup, err)
+			}
+		}
+	}()
+}
+
+// backups returns the backups lumberjack created for the file, named
+// like app-2006-01-02T15-04-05.000.log for app.log. Other files sharing
+// the prefix, such as app-errors.log, are left out.
+func (s *compressingSink) backups() []string {
+	ext := filepath.Ext(s.Filename)
+	prefix := strings.TrimSuffix(s.Filename, ext) + "-"
+
+	matches, _ := filepath.Glob(escapeGlob(prefix) + "*" + escapeGlob(ext))
+
+	var backups []string
+	for _, m := range matches {
+		timestamp := strings.TrimSuffix(strings.TrimPrefix(m, prefix), ext)
+		if _, err := time.Parse(backupTimeFormat, timestamp); err == nil {
+			backups = append(backups, m)
+		}
+	}
+
+	return backups
+}
+
+func (s *compressingSink) compressBackup(path string) error {
+	if err := s.compress(path); err != nil {
+		return err
+	}
+
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	return nil
+}
+
+// escapeGlob escapes the characters filepath.Match treats specially.
+func escapeGlob(s string) string {
+	return strings.NewReplacer(`*`, `\*`, `?`, `\?`, `[`, `\[`).Replace(s)
+}
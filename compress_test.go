@@ -0,0 +1,76 @@
+package logger
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// recordingCompressor is a Config.Compressor copying every file it is
+// given to a .z file and recording its path.
+type recordingCompressor struct {
+	mu    sync.Mutex
+	paths []string
+}
+
+func (c *recordingCompressor) compress(path string) error {
+	c.mu.Lock()
+	c.paths = append(c.paths, path)
+	c.mu.Unlock()
+
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(path+".z", b, 0o644)
+}
+
+func TestCompressorOnRotate(t *testing.T) {
+	resetForTest(t)
+
+	dir := t.TempDir()
+	path, errorsPath := filepath.Join(dir, "app.log"), filepath.Join(dir, "app-errors.log")
+	c := &recordingCompressor{}
+	l := Get(path, "info", Config{
+		Compressor: c.compress,
+		Files:      []FileSpec{{Path: errorsPath}},
+		Quiet:      true,
+	})
+
+	l.Info("rotated away")
+	if err := Rotate(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Waits for the compression to finish
+	if err := Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(c.paths) != 1 {
+		t.Fatalf("compressor called with %v, want one backup", c.paths)
+	}
+
+	backup := filepath.Base(c.paths[0])
+	timestamp := strings.TrimSuffix(strings.TrimPrefix(backup, "app-"), ".log")
+	if _, err := time.Parse(backupTimeFormat, timestamp); err != nil {
+		t.Errorf("compressor called with %s, want a lumberjack backup", backup)
+	}
+
+	if _, err := os.Stat(c.paths[0]); !os.IsNotExist(err) {
+		t.Error("backup not deleted once compressed")
+	}
+
+	b, err := os.ReadFile(c.paths[0] + ".z")
+	if err != nil || !strings.Contains(string(b), "rotated away") {
+		t.Errorf("compressed copy = %q, %v", b, err)
+	}
+
+	if b, err := os.ReadFile(errorsPath); err != nil || !strings.Contains(string(b), "rotated away") {
+		t.Errorf("app-errors.log = %q, %v, want it left alone", b, err)
+	}
+}
@@ -0,0 +1,445 @@
+package logger
+
+import (
+	"fmt"
+	"io"
+	"time"
+
+	"go.uber.org/zap/zapcore"
+	"gopkg.in/natefinch/lumberjack.v2"
+)
+
+const (
+	defaultMaxSizeMB  = 100 // log size 100MB
+	defaultMaxBackups = 30  // Keeps last 30 log files
+	defaultCompress   = true
+	defaultTimeKey    = "timestamp"
+)
+
+// NoStacktrace disables stack traces when used as Config.StacktraceLevel.
+const NoStacktrace = zapcore.InvalidLevel
+
+// Supported values of Config.Format.
+const (
+	FormatJSON    = "json"
+	FormatConsole = "console"
+	FormatLogfmt  = "logfmt"
+)
+
+// Config holds the optional settings used by Get. Zero values fall back
+// to the package defaults so existing callers keep the same behaviour.
+type Config struct {
+	// Path and Level are the log file and level used by GetWith and
+	// GetWithE. Get takes them as arguments instead and ignores these.
+	Path  string
+	Level string
+
+	// MaxSizeMB is the size in megabytes a log file may reach before it
+	// is rotated. Defaults to 100.
+	MaxSizeMB int
+
+	// MaxBackups is the number of rotated log files to keep.
+	// Defaults to 30.
+	MaxBackups int
+
+	// MaxAgeDays is the number of days rotated log files are kept for.
+	// Zero keeps them regardless of age. MaxBackups and MaxAgeDays apply
+	// together: a rotated file is deleted as soon as either one says so,
+	// whichever triggers first.
+	MaxAgeDays int
+
+	// Compress determines whether rotated log files are gzipped.
+	// Defaults to true when nil.
+	Compress *bool
+
+	// RepairPartialLine truncates the trailing partial line a crash may
+	// have left in an existing JSON log file before appending to it, so
+	// that parsers reading the file line by line do not choke. Rotated
+	// files are left alone.
+	RepairPartialLine bool
+
+	// Compressor, when set, replaces the gzip compression of rotated log
+	// files, e.g. with zstd. It is called in the background with the path
+	// of every rotated file and should write its compressed copy next to
+	// it; the rotated file is deleted once it returns nil. The compressed
+	// copies are not counted by MaxBackups and MaxAgeDays.
+	Compressor func(path string) error
+
+	// RotateDaily starts a new log file every day at midnight, with the
+	// date inserted in its name: app.log becomes app-2024-06-01.log.
+	// Files are still rotated by size within a day, MaxBackups and
+	// MaxAgeDays applying to the rotated files of each day. They also
+	// apply to the days: when a new day starts, the files of the days
+	// beyond the MaxBackups most recent ones or older than MaxAgeDays are
+	// deleted, including their rotated files.
+	RotateDaily bool
+
+	// Location is the time zone whose midnight starts a new file when
+	// RotateDaily is set. Defaults to time.Local.
+	Location *time.Location
+
+	// Format selects the encoding used for every destination, FormatJSON,
+	// FormatConsole or FormatLogfmt. When empty the console gets FormatConsole and the
+	// file gets FormatJSON.
+	Format string
+
+	// PrettyJSON writes indented JSON spanning several lines to the
+	// console, whatever Format is, e.g. to read nested fields in
+	// development. The file is not affected.
+	PrettyJSON bool
+
+	// Color forces the colored levels of the console format on or off.
+	// When nil they are colored only when writing to a terminal.
+	Color *bool
+
+	// StdoutOnly writes every entry to stdout in the file format, JSON by
+	// default, and opens no file whatever APP_ENV, Writer and Files are,
+	// e.g. for serverless platforms whose filesystem is ephemeral.
+	StdoutOnly bool
+
+	// ErrorOutput, when set, additionally receives every entry at
+	// ErrorLevel or above, e.g. os.Stderr. It uses the console format.
+	ErrorOutput io.Writer
+
+	// Async, when set, buffers the entries written to the file so that
+	// logging does not wait on disk writes. Buffered entries are written
+	// out periodically and by Sync and Close.
+	Async *AsyncConfig
+
+	// Files are additional log files, each receiving the entries enabled
+	// by its Levels in the file format whatever APP_ENV is. For example
+	//
+	//	Files: []FileSpec{
+	//		{Path: "logs/error.log", Levels: zapcore.ErrorLevel},
+	//		{Path: "logs/combined.log"},
+	//	},
+	//
+	// keeps the errors apart as well as in a file holding everything.
+	// They are rotated like the main file unless their FileSpec says
+	// otherwise.
+	Files []FileSpec
+
+	// FlushInterval, when set, syncs every destination periodically in the
+	// background until Close, so that buffered entries, e.g. with Async,
+	// are written out during idle periods.
+	FlushInterval time.Duration
+
+	// Syslog, when set, additionally sends every entry to a syslog daemon
+	// using the file format. It is not supported on Windows and Plan 9.
+	Syslog *SyslogConfig
+
+	// Journald, when set, additionally sends every entry to journald with
+	// its fields as journal fields and its level as priority. It is only
+	// supported on Linux; when journald cannot be reached the entries are
+	// written to stderr instead.
+	Journald *JournaldConfig
+
+	// NetworkSink, when set, additionally ships every entry as JSON lines
+	// to a collector such as Logstash or Fluentd.
+	NetworkSink *NetworkConfig
+
+	// Fields are added to every entry, whatever the destination, e.g.
+	// the service name, environment and version.
+	Fields map[string]string
+
+	// OmitHostFields leaves out the hostname and pid fields every entry
+	// otherwise carries, e.g. when the collector adds them.
+	OmitHostFields bool
+
+	// Schema, when set, reports the fields of entries whose key or type
+	// is not allowed by it, to catch key drift. Fields, the build info and
+	// the host fields added to every entry are not checked.
+	Schema *SchemaConfig
+
+	// Sampling, when set, caps the volume of repeated entries. Sampling
+	// is disabled by default.
+	Sampling *SamplingConfig
+
+	// Dedup, when set, collapses the entries repeating the level and
+	// message of an entry logged within the window into that entry, which
+	// is written at the end of the window with a repeat_count field.
+	// Entries are delayed by up to the window, or until Sync.
+	Dedup *DedupConfig
+
+	// TimeKey is the key of the entry timestamp. Defaults to "timestamp".
+	TimeKey string
+
+	// TimeEncoder formats the entry timestamp, e.g.
+	// zapcore.EpochMillisTimeEncoder or zapcore.RFC3339NanoTimeEncoder.
+	// Defaults to zapcore.ISO8601TimeEncoder.
+	TimeEncoder zapcore.TimeEncoder
+
+	// LineEnding terminates every entry, e.g. "\r\n" for Windows tools.
+	// Defaults to "\n".
+	LineEnding string
+
+	// UTC formats timestamps in UTC instead of the local time zone.
+	UTC bool
+
+	// Hooks are called with every entry that is logged, e.g. to count
+	// entries per level in a metric. They run synchronously on the
+	// logging goroutine so they must be fast and must not block.
+	Hooks []func(zapcore.Entry) error
+
+	// Clock provides the time of every entry, e.g. a fixed time in tests.
+	// Defaults to the system clock.
+	Clock zapcore.Clock
+
+	// DurationEncoder formats duration fields, e.g.
+	// zapcore.StringDurationEncoder. Defaults to zapcore.SecondsDurationEncoder.
+	DurationEncoder zapcore.DurationEncoder
+
+	// CallerEncoder formats the caller when AddCaller is set, e.g.
+	// zapcore.FullCallerEncoder. Defaults to zapcore.ShortCallerEncoder.
+	CallerEncoder zapcore.CallerEncoder
+
+	// Writer, when set, replaces the log file, e.g. with a buffer or a
+	// socket. Entries written to it are not rotated.
+	Writer io.Writer
+
+	// DebugSampledTraces makes FromCtx log debug entries, whatever the
+	// level, when the context carries a sampled OpenTelemetry span, so
+	// that the requests that are traced are logged in full.
+	DebugSampledTraces bool
+
+	// StacktraceLevel sets the levels at which a stack trace is captured,
+	// e.g. zapcore.WarnLevel, or NoStacktrace to disable stack traces.
+	// Defaults to zapcore.ErrorLevel.
+	StacktraceLevel zapcore.LevelEnabler
+
+	// AddCaller annotates every entry with the file and line it was
+	// logged from.
+	AddCaller bool
+
+	// CallerSkip is the number of stack frames skipped when reporting the
+	// caller, for applications logging through their own helpers.
+	CallerSkip int
+
+	// Quiet leaves out the info entry summarizing the configuration that
+	// is otherwise logged once the logger is built, e.g. in tests.
+	Quiet bool
+
+	// FatalPanics makes Fatal entries panic instead of exiting the
+	// process, so that tests can recover. Either way every destination
+	// is flushed first.
+	FatalPanics bool
+
+	// ConsoleLevels and FileLevels restrict the entries written to the
+	// console and to the file, on top of the logger level. For example
+	//
+	//	ConsoleLevels: zap.LevelEnablerFunc(func(l zapcore.Level) bool {
+	//		return l < zapcore.WarnLevel
+	//	}),
+	//	FileLevels: zapcore.WarnLevel,
+	//
+	// keeps debug and info entries on the console and persists warnings
+	// and errors. Setting either writes to both destinations whatever
+	// APP_ENV is.
+	ConsoleLevels zapcore.LevelEnabler
+	FileLevels    zapcore.LevelEnabler
+
+	// ConsoleMinLevel and FileMinLevel replace the logger level for the
+	// console and for the file, e.g. zap.NewAtomicLevelAt(zap.WarnLevel)
+	// to keep the terminal quiet while the file gets debug entries in
+	// "both" mode. SetLevel no longer applies to a destination whose
+	// level is replaced.
+	ConsoleMinLevel zapcore.LevelEnabler
+	FileMinLevel    zapcore.LevelEnabler
+
+	// Redact, when set, masks sensitive fields on every destination.
+	Redact *Redactor
+
+	// Keys renames the keys of the fields every entry carries.
+	Keys Keys
+}
+
+// Keys holds the keys of the fields every entry carries. Empty keys keep
+// zap's production names: "msg", "level", "caller", "logger" and
+// "stacktrace", and "timestamp" for the time. TimeKey takes precedence
+// over Config.TimeKey.
+type Keys struct {
+	MessageKey    string
+	LevelKey      string
+	CallerKey     string
+	NameKey       string
+	TimeKey       string
+	StacktraceKey string
+}
+
+// apply sets the non-empty keys of k on encoderCfg.
+func (k Keys) apply(encoderCfg *zapcore.EncoderConfig) {
+	set := func(dst *string, key string) {
+		if key != "" {
+			*dst = key
+		}
+	}
+
+	set(&encoderCfg.MessageKey, k.MessageKey)
+	set(&encoderCfg.LevelKey, k.LevelKey)
+	set(&encoderCfg.CallerKey, k.CallerKey)
+	set(&encoderCfg.NameKey, k.NameKey)
+	set(&encoderCfg.TimeKey, k.TimeKey)
+	set(&encoderCfg.StacktraceKey, k.StacktraceKey)
+}
+
+// FileSpec describes one of Config.Files.
+type FileSpec struct {
+	// Path is the log file.
+	Path string
+
+	// Levels restricts the entries written to the file, on top of the
+	// logger level. A nil Levels writes every entry.
+	Levels zapcore.LevelEnabler
+
+	// MaxSizeMB, MaxBackups, MaxAgeDays and Compress override the
+	// rotation settings of the Config for this file when set.
+	MaxSizeMB  int
+	MaxBackups int
+	MaxAgeDays int
+	Compress   *bool
+}
+
+// config returns cfg with the rotation settings of s applied.
+func (s FileSpec) config(cfg Config) Config {
+	if s.MaxSizeMB > 0 {
+		cfg.MaxSizeMB = s.MaxSizeMB
+	}
+
+	if s.MaxBackups > 0 {
+		cfg.MaxBackups = s.MaxBackups
+	}
+
+	if s.MaxAgeDays > 0 {
+		cfg.MaxAgeDays = s.MaxAgeDays
+	}
+
+	if s.Compress != nil {
+		cfg.Compress = s.Compress
+	}
+
+	return cfg
+}
+
+// AsyncConfig configures the buffering of the file.
+type AsyncConfig struct {
+	// BufferSize is the number of bytes buffered before they are written
+	// out. Defaults to 256 kB.
+	BufferSize int
+
+	// FlushInterval is how often buffered entries are written out.
+	// Defaults to 30 seconds.
+	FlushInterval time.Duration
+}
+
+// SamplingConfig configures sampling of log entries. Within each second
+// the first First entries with the same level and message are logged,
+// then only every Thereafter-th one; the others are dropped, so
+// duplicate messages during a burst are lost.
+type SamplingConfig struct {
+	First      int
+	Thereafter int
+
+	// KeepErrors exempts entries at ErrorLevel and above from sampling,
+	// so that only debug, info and warn entries are thinned.
+	KeepErrors bool
+
+	// ReportInterval is how often a warning counting the entries dropped
+	// since the last one is written, if any were. The count is also
+	// written by Sync and Close. Defaults to one minute.
+	ReportInterval time.Duration
+}
+
+// firstConfig returns the first of the optional configs passed to Get,
+// or the zero Config if none was given.
+func firstConfig(cfg []Config) Config {
+	if len(cfg) > 0 {
+		return cfg[0]
+	}
+
+	return Config{}
+}
+
+// files returns the Files to write to, none when StdoutOnly is set.
+func (c Config) files() []FileSpec {
+	if c.StdoutOnly {
+		return nil
+	}
+
+	return c.Files
+}
+
+// lineEnding returns the line ending of c with the default applied.
+func (c Config) lineEnding() string {
+	if c.LineEnding == "" {
+		return zapcore.DefaultLineEnding
+	}
+
+	return c.LineEnding
+}
+
+// color reports whether console output is colored, given whether it is
+// written to a terminal.
+func (c Config) color(terminal bool) bool {
+	if c.Color != nil {
+		return *c.Color
+	}
+
+	return terminal
+}
+
+// repair applies RepairPartialLine to the log file at path.
+func (c Config) repair(path string) error {
+	if !c.RepairPartialLine || (c.Format != "" && c.Format != FormatJSON) {
+		return nil
+	}
+
+	if err := repairPartialLine(path); err != nil {
+		return fmt.Errorf("failed to repair log file: %w", err)
+	}
+
+	return nil
+}
+
+// rotation returns the rotation settings of c with the defaults
+// applied.
+func (c Config) rotation() (maxSizeMB, maxBackups int, compress bool) {
+	maxSizeMB = c.MaxSizeMB
+	if maxSizeMB <= 0 {
+		maxSizeMB = defaultMaxSizeMB
+	}
+
+	maxBackups = c.MaxBackups
+	if maxBackups <= 0 {
+		maxBackups = defaultMaxBackups
+	}
+
+	compress = defaultCompress
+	if c.Compress != nil {
+		compress = *c.Compress
+	}
+
+	return maxSizeMB, maxBackups, compress
+}
+
+// newFileSink builds the rotating file sink for logPath from cfg.
+func newFileSink(logPath string, cfg Config) fileSink {
+	maxSize, maxBackups, compress := cfg.rotation()
+
+	if cfg.Compressor != nil {
+		compress = false
+	}
+
+	file := &lumberjack.Logger{
+		Filename:   logPath,
+		MaxSize:    maxSize,
+		MaxBackups: maxBackups,
+		MaxAge:     cfg.MaxAgeDays,
+		Compress:   compress,
+	}
+
+	if cfg.Compressor != nil {
+		return newCompressingSink(file, cfg.Compressor)
+	}
+
+	return file
+}
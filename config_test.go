@@ -0,0 +1,133 @@
+package logger
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"gopkg.in/natefinch/lumberjack.v2"
+)
+
+// rotationSettings are the settings of a lumberjack.Logger set from a
+// Config.
+type rotationSettings struct {
+	MaxSize    int
+	MaxBackups int
+	MaxAge     int
+	Compress   bool
+}
+
+func rotationOf(l *lumberjack.Logger) rotationSettings {
+	return rotationSettings{MaxSize: l.MaxSize, MaxBackups: l.MaxBackups, MaxAge: l.MaxAge, Compress: l.Compress}
+}
+
+func TestGetRotationConfig(t *testing.T) {
+	compress := false
+
+	tests := []struct {
+		name string
+		cfg  Config
+		want rotationSettings
+	}{
+		{
+			name: "defaults",
+			want: rotationSettings{MaxSize: 100, MaxBackups: 30, Compress: true},
+		},
+		{
+			name: "custom",
+			cfg:  Config{MaxSizeMB: 5, MaxBackups: 3, MaxAgeDays: 7, Compress: &compress},
+			want: rotationSettings{MaxSize: 5, MaxBackups: 3, MaxAge: 7, Compress: false},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resetForTest(t)
+
+			path := filepath.Join(t.TempDir(), "app.log")
+			tt.cfg.Quiet = true
+			Get(path, "info", tt.cfg)
+
+			file, ok := defaultOutput.file.(*lumberjack.Logger)
+			if !ok {
+				t.Fatalf("file is %T, want *lumberjack.Logger", defaultOutput.file)
+			}
+
+			if file.Filename != path {
+				t.Errorf("Filename = %q, want %q", file.Filename, path)
+			}
+
+			if got := rotationOf(file); got != tt.want {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestKeys(t *testing.T) {
+	l, buf := newTestLogger(t, "info", Config{Keys: Keys{MessageKey: "message", LevelKey: "severity"}})
+
+	l.Info("renamed")
+
+	entries := decodeEntries(t, buf.String())
+	if entries[0]["message"] != "renamed" || entries[0]["severity"] != "info" {
+		t.Errorf("got %v, want message and severity keys", entries[0])
+	}
+
+	if _, ok := entries[0]["msg"]; ok {
+		t.Error("msg key still written")
+	}
+}
+
+func TestNewFileSinkMaxAge(t *testing.T) {
+	sink := newFileSink(filepath.Join(t.TempDir(), "app.log"), Config{MaxSizeMB: 10, MaxAgeDays: 14})
+
+	file, ok := sink.(*lumberjack.Logger)
+	if !ok {
+		t.Fatalf("sink is %T, want *lumberjack.Logger", sink)
+	}
+
+	if file.MaxAge != 14 || file.MaxSize != 10 {
+		t.Errorf("MaxAge = %d and MaxSize = %d, want 14 and 10", file.MaxAge, file.MaxSize)
+	}
+}
+
+func TestFileSpecRotation(t *testing.T) {
+	dir := t.TempDir()
+	debugLog, auditLog := filepath.Join(dir, "debug.log"), filepath.Join(dir, "audit.log")
+	newTestLogger(t, "info", Config{MaxBackups: 10, Files: []FileSpec{
+		{Path: debugLog, MaxSizeMB: 1, MaxBackups: 2},
+		{Path: auditLog, MaxBackups: 90, MaxAgeDays: 365},
+	}})
+
+	got := map[string]rotationSettings{}
+	for _, c := range defaultOutput.closers {
+		if file, ok := c.(*lumberjack.Logger); ok {
+			got[file.Filename] = rotationOf(file)
+		}
+	}
+
+	want := map[string]rotationSettings{
+		debugLog: {MaxSize: 1, MaxBackups: 2, Compress: true},
+		auditLog: {MaxSize: 100, MaxBackups: 90, MaxAge: 365, Compress: true},
+	}
+	for path, w := range want {
+		if got[path] != w {
+			t.Errorf("%s: got %+v, want %+v", filepath.Base(path), got[path], w)
+		}
+	}
+}
+
+func TestLineEnding(t *testing.T) {
+	for _, format := range []string{FormatJSON, FormatConsole, FormatLogfmt} {
+		l, buf := newTestLogger(t, "info", Config{Format: format, LineEnding: "\r\n"})
+
+		l.Info("first")
+		l.Info("second")
+
+		got := buf.String()
+		if strings.Count(got, "\r\n") != 2 || strings.Count(got, "\n") != 2 || !strings.HasSuffix(got, "\r\n") {
+			t.Errorf("%s: got %q, want each entry terminated by CRLF", format, got)
+		}
+	}
+}
@@ -0,0 +1,48 @@
+package logger
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+
+	"go.uber.org/zap"
+)
+
+type correlationIDKey struct{}
+
+// EnsureCorrelationID returns ctx and the correlation id it carries. If
+// it carries none, a new UUID is stored with WithCorrelationID first.
+func EnsureCorrelationID(ctx context.Context) (context.Context, string) {
+	if id := CorrelationIDFromCtx(ctx); id != "" {
+		return ctx, id
+	}
+
+	id := newUUID()
+	return WithCorrelationID(ctx, id), id
+}
+
+// WithCorrelationID returns a copy of ctx carrying the correlation id,
+// e.g. one received from an upstream service, and a child of its Logger
+// adding it to every entry as correlation_id.
+func WithCorrelationID(ctx context.Context, id string) context.Context {
+	ctx = context.WithValue(ctx, correlationIDKey{}, id)
+	return WithFields(ctx, zap.String("correlation_id", id))
+}
+
+// CorrelationIDFromCtx returns the correlation id stored in ctx, or an
+// empty string if there is none.
+func CorrelationIDFromCtx(ctx context.Context) string {
+	id, _ := ctx.Value(correlationIDKey{}).(string)
+	return id
+}
+
+// newUUID returns a random version 4 UUID.
+func newUUID() string {
+	var b [16]byte
+	_, _ = rand.Read(b[:])
+
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:])
+}
@@ -0,0 +1,26 @@
+package logger
+
+import (
+	"context"
+	"regexp"
+	"testing"
+)
+
+func TestEnsureCorrelationID(t *testing.T) {
+	_, buf := newTestLogger(t, "info", Config{})
+
+	ctx, id := EnsureCorrelationID(WithCorrelationID(context.Background(), "upstream-id"))
+	if id != "upstream-id" || CorrelationIDFromCtx(ctx) != "upstream-id" {
+		t.Errorf("id = %q, want the existing upstream-id", id)
+	}
+
+	ctx, id = EnsureCorrelationID(context.Background())
+	if !regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`).MatchString(id) {
+		t.Errorf("generated id %q is not a UUID", id)
+	}
+
+	FromCtx(ctx).Info("correlated")
+	if got := decodeEntries(t, buf.String())[0]["correlation_id"]; got != id {
+		t.Errorf("correlation_id = %v, want %s", got, id)
+	}
+}
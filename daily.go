@@ -0,0 +1,207 @@
+package logger
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/multierr"
+	"go.uber.org/zap/zapcore"
+)
+
+const (
+	// dateLayout is the layout of the date inserted in the file names.
+	dateLayout = "2006-01-02"
+
+	// backupTimeFormat is the timestamp lumberjack inserts in the names
+	// of the backups it rotates a file to.
+	backupTimeFormat = "2006-01-02T15-04-05.000"
+)
+
+// dailySink writes to a file named after the current date, e.g.
+// app-2024-06-01.log for app.log, and switches to a new file at
+// midnight. Within a day the file is still rotated by size.
+type dailySink struct {
+	logPath string
+	cfg     Config
+	clock   zapcore.Clock
+	loc     *time.Location
+
+	mu   sync.Mutex
+	date string
+	file fileSink
+}
+
+// newDailySink returns the daily sink for logPath configured by cfg.
+func newDailySink(logPath string, cfg Config) *dailySink {
+	clock := cfg.Clock
+	if clock == nil {
+		clock = zapcore.DefaultClock
+	}
+
+	loc := cfg.Location
+	if loc == nil {
+		loc = time.Local
+	}
+
+	return &dailySink{logPath: logPath, cfg: cfg, clock: clock, loc: loc}
+}
+
+// today returns the current date in the location of s.
+func (s *dailySink) today() string {
+	return s.clock.Now().In(s.loc).Format(dateLayout)
+}
+
+// currentPath returns the file written to today.
+func (s *dailySink) currentPath() string {
+	return datedPath(s.logPath, s.today())
+}
+
+// datedPath returns logPath with date inserted before its extension.
+func datedPath(logPath, date string) string {
+	ext := filepath.Ext(logPath)
+	return strings.TrimSuffix(logPath, ext) + "-" + date + ext
+}
+
+func (s *dailySink) Write(p []byte) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var err error
+	date := s.today()
+	if date != s.date {
+		if s.file != nil {
+			err = s.file.Close()
+		}
+
+		s.date = date
+		path := datedPath(s.logPath, date)
+		err = multierr.Append(err, s.cfg.repair(path))
+		err = multierr.Append(err, s.prune())
+		s.file = newFileSink(path, s.cfg)
+	}
+
+	n, writeErr := s.file.Write(p)
+	return n, multierr.Append(err, writeErr)
+}
+
+// prune deletes the files of the days before s.date that are no longer
+// kept: those beyond the MaxBackups most recent days and those older
+// than MaxAgeDays, along with their rotated and compressed files.
+func (s *dailySink) prune() error {
+	_, maxBackups, _ := s.cfg.rotation()
+
+	current, err := time.ParseInLocation(dateLayout, s.date, s.loc)
+	if err != nil {
+		return err
+	}
+
+	cutoff := current.AddDate(0, 0, -s.cfg.MaxAgeDays)
+
+	byDate := make(map[string][]string)
+	var dates []string
+	for _, path := range s.datedFiles() {
+		date := s.dateOf(path)
+		if date >= s.date {
+			continue
+		}
+
+		if byDate[date] == nil {
+			dates = append(dates, date)
+		}
+
+		byDate[date] = append(byDate[date], path)
+	}
+
+	// Most recent first
+	sort.Sort(sort.Reverse(sort.StringSlice(dates)))
+
+	var errs error
+	for i, date := range dates {
+		day, _ := time.ParseInLocation(dateLayout, date, s.loc)
+		if i < maxBackups && (s.cfg.MaxAgeDays <= 0 || !day.Before(cutoff)) {
+			continue
+		}
+
+		for _, path := range byDate[date] {
+			if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+				errs = multierr.Append(errs, err)
+			}
+		}
+	}
+
+	return errs
+}
+
+// datedFiles returns the files written by s on any day: the dated files,
+// the backups lumberjack rotated them to, and their compressed copies.
+func (s *dailySink) datedFiles() []string {
+	ext := filepath.Ext(s.logPath)
+	matches, _ := filepath.Glob(escapeGlob(strings.TrimSuffix(s.logPath, ext)) + "-*")
+
+	var files []string
+	for _, m := range matches {
+		if s.dateOf(m) != "" {
+			files = append(files, m)
+		}
+	}
+
+	return files
+}
+
+// dateOf returns the date in the name of path if it is one of the files
+// written by s, or an empty string.
+func (s *dailySink) dateOf(path string) string {
+	ext := filepath.Ext(s.logPath)
+	prefix := strings.TrimSuffix(s.logPath, ext) + "-"
+
+	rest := strings.TrimPrefix(path, prefix)
+	if rest == path || len(rest) < len(dateLayout) {
+		return ""
+	}
+
+	date, rest := rest[:len(dateLayout)], rest[len(dateLayout):]
+	if _, err := time.Parse(dateLayout, date); err != nil {
+		return ""
+	}
+
+	// A rotated file has a timestamp between the date and the extension
+	if strings.HasPrefix(rest, "-") && len(rest) > len(backupTimeFormat) {
+		if _, err := time.Parse(backupTimeFormat, rest[1:1+len(backupTimeFormat)]); err == nil {
+			rest = rest[1+len(backupTimeFormat):]
+		}
+	}
+
+	// Compressed copies have an extension of their own
+	if rest != ext && !strings.HasPrefix(rest, ext+".") {
+		return ""
+	}
+
+	return date
+}
+
+// Rotate rotates the file of the current day.
+func (s *dailySink) Rotate() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.file == nil {
+		return nil
+	}
+
+	return s.file.Rotate()
+}
+
+func (s *dailySink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.file == nil {
+		return nil
+	}
+
+	return s.file.Close()
+}
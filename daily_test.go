@@ -0,0 +1,143 @@
+package logger
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// movingClock is a zapcore.Clock whose time is set by the test.
+type movingClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func (c *movingClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.now
+}
+
+func (c *movingClock) set(t time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.now = t
+}
+
+func (c *movingClock) NewTicker(d time.Duration) *time.Ticker { return time.NewTicker(d) }
+
+// dirFiles returns the names of the files in dir, sorted.
+func dirFiles(t *testing.T, dir string) []string {
+	t.Helper()
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var names []string
+	for _, e := range entries {
+		names = append(names, e.Name())
+	}
+
+	sort.Strings(names)
+	return names
+}
+
+func TestRotateDailyCrossesMidnight(t *testing.T) {
+	resetForTest(t)
+
+	dir := t.TempDir()
+	clock := &movingClock{now: time.Date(2024, 6, 1, 23, 59, 0, 0, time.UTC)}
+	l := Get(filepath.Join(dir, "app.log"), "info", Config{
+		RotateDaily: true,
+		Location:    time.UTC,
+		Clock:       clock,
+		Quiet:       true,
+	})
+
+	l.Info("before midnight")
+	clock.set(time.Date(2024, 6, 2, 0, 1, 0, 0, time.UTC))
+	l.Info("after midnight")
+	_ = Sync()
+
+	if got, want := strings.Join(dirFiles(t, dir), " "), "app-2024-06-01.log app-2024-06-02.log"; got != want {
+		t.Fatalf("files = %s, want %s", got, want)
+	}
+
+	for name, want := range map[string]string{
+		"app-2024-06-01.log": "before midnight",
+		"app-2024-06-02.log": "after midnight",
+	} {
+		b, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if entries := decodeEntries(t, string(b)); len(entries) != 1 || entries[0]["msg"] != want {
+			t.Errorf("%s = %v, want only %q", name, entries, want)
+		}
+	}
+}
+
+func TestRotateDailyPrunesOldDays(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  Config
+		want string
+	}{
+		{
+			name: "MaxBackups",
+			cfg:  Config{MaxBackups: 2},
+			want: "app-2024-06-07.log app-2024-06-08-2024-06-08T10-00-00.000.log.gz app-2024-06-08.log app-2024-06-10.log app-errors.log",
+		},
+		{
+			name: "MaxAgeDays",
+			cfg:  Config{MaxAgeDays: 4},
+			want: "app-2024-06-07.log app-2024-06-08-2024-06-08T10-00-00.000.log.gz app-2024-06-08.log app-2024-06-10.log app-errors.log",
+		},
+		{
+			name: "defaults",
+			want: "app-2024-05-01-2024-05-01T10-00-00.000.log app-2024-05-01.log app-2024-06-01.log.zst " +
+				"app-2024-06-07.log app-2024-06-08-2024-06-08T10-00-00.000.log.gz app-2024-06-08.log app-2024-06-10.log app-errors.log",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			for _, name := range []string{
+				"app-2024-05-01.log",
+				"app-2024-05-01-2024-05-01T10-00-00.000.log",
+				"app-2024-06-01.log.zst",
+				"app-2024-06-07.log",
+				"app-2024-06-08.log",
+				"app-2024-06-08-2024-06-08T10-00-00.000.log.gz",
+				"app-errors.log",
+			} {
+				if err := os.WriteFile(filepath.Join(dir, name), []byte("{}\n"), 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			tt.cfg.Clock = &movingClock{now: time.Date(2024, 6, 10, 8, 0, 0, 0, time.UTC)}
+			tt.cfg.Location = time.UTC
+			s := newDailySink(filepath.Join(dir, "app.log"), tt.cfg)
+			defer s.Close()
+
+			if _, err := s.Write([]byte("{}\n")); err != nil {
+				t.Fatal(err)
+			}
+
+			if got := strings.Join(dirFiles(t, dir), " "); got != tt.want {
+				t.Errorf("files = %s\nwant    %s", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,158 @@
+package logger
+
+import (
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// DedupConfig configures the collapsing of repeated entries.
+type DedupConfig struct {
+	// Window is how long an entry is held back while its repeats are
+	// counted. Defaults to one second.
+	Window time.Duration
+}
+
+// dedupKey identifies the entries collapsed together.
+type dedupKey struct {
+	level   zapcore.Level
+	message string
+}
+
+// dedupEntry is an entry held back until its window closes, with the
+// destinations the wrapped core accepted it for.
+type dedupEntry struct {
+	ce     *zapcore.CheckedEntry
+	ent    zapcore.Entry
+	fields []zapcore.Field
+	count  int
+	timer  *time.Timer
+}
+
+// dedupState holds the entries held back by a dedupCore and its
+// children.
+type dedupState struct {
+	window time.Duration
+
+	mu      sync.Mutex
+	pending map[dedupKey]*dedupEntry
+}
+
+// dedupCore is a zapcore.Core holding back every entry for a window and
+// writing it once with a repeat_count field counting the entries with the
+// same level and message logged in the meantime. Entries above
+// ErrorLevel are written straight away since they may end the process.
+type dedupCore struct {
+	zapcore.Core
+	state *dedupState
+}
+
+// newDedupCore returns core collapsing repeated entries as configured by
+// cfg.
+func newDedupCore(core zapcore.Core, cfg DedupConfig) zapcore.Core {
+	window := cfg.Window
+	if window <= 0 {
+		window = time.Second
+	}
+
+	return &dedupCore{
+		Core:  core,
+		state: &dedupState{window: window, pending: make(map[dedupKey]*dedupEntry)},
+	}
+}
+
+func (c *dedupCore) With(fields []zapcore.Field) zapcore.Core {
+	return &dedupCore{Core: c.Core.With(fields), state: c.state}
+}
+
+// Check checks ent against the wrapped core straight away, as a core
+// wrapping c may have checked it as another level, e.g. to force it
+// through with WithForceLevel, and holds it back in a core of its own.
+func (c *dedupCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if ent.Level > zapcore.ErrorLevel {
+		return c.Core.Check(ent, ce)
+	}
+
+	checked := c.Core.Check(ent, nil)
+	if checked == nil {
+		return ce
+	}
+
+	return ce.AddCore(ent, dedupWriter{dedupCore: c, checked: checked})
+}
+
+// dedupWriter is the zapcore.Core holding back an entry checked by a
+// dedupCore until it is written to its checked destinations.
+type dedupWriter struct {
+	*dedupCore
+	checked *zapcore.CheckedEntry
+}
+
+func (w dedupWriter) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	key := dedupKey{level: ent.Level, message: ent.Message}
+
+	w.state.mu.Lock()
+	defer w.state.mu.Unlock()
+
+	if p, ok := w.state.pending[key]; ok {
+		p.count++
+		return nil
+	}
+
+	p := &dedupEntry{
+		ce:     w.checked,
+		ent:    ent,
+		fields: append([]zapcore.Field(nil), fields...),
+		count:  1,
+	}
+	p.timer = time.AfterFunc(w.state.window, func() {
+		w.state.flush(key, p)
+	})
+	w.state.pending[key] = p
+
+	return nil
+}
+
+// Sync writes the entries held back before syncing.
+func (c *dedupCore) Sync() error {
+	c.state.mu.Lock()
+	pending := c.state.pending
+	c.state.pending = make(map[dedupKey]*dedupEntry)
+	c.state.mu.Unlock()
+
+	for _, p := range pending {
+		p.timer.Stop()
+		p.write()
+	}
+
+	return c.Core.Sync()
+}
+
+// flush writes p once its window is over, unless Sync did already.
+func (s *dedupState) flush(key dedupKey, p *dedupEntry) {
+	s.mu.Lock()
+	if s.pending[key] != p {
+		s.mu.Unlock()
+		return
+	}
+
+	delete(s.pending, key)
+	s.mu.Unlock()
+
+	p.write()
+}
+
+// write writes the held back entry to its destinations, with its
+// repeat count if it was repeated.
+func (p *dedupEntry) write() {
+	fields := p.fields
+	if p.count > 1 {
+		fields = append(fields, zap.Int("repeat_count", p.count))
+	}
+
+	// The entry as written, with its level, caller and stack
+	p.ce.Entry = p.ent
+	p.ce.Write(fields...)
+}
@@ -0,0 +1,57 @@
+package logger
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+func TestDedup(t *testing.T) {
+	l, buf := newTestLogger(t, "info", Config{Dedup: &DedupConfig{Window: time.Hour}})
+
+	for i := 0; i < 50; i++ {
+		l.Error("db down")
+	}
+	l.Error("other")
+	Sync()
+
+	entries := decodeEntries(t, buf.String())
+	if len(entries) != 2 {
+		t.Fatalf("got %d entries, want 2", len(entries))
+	}
+
+	counts := map[interface{}]interface{}{}
+	for _, e := range entries {
+		counts[e["msg"]] = e["repeat_count"]
+	}
+
+	if got := counts["db down"]; got != float64(50) {
+		t.Errorf("repeat_count of db down = %v, want 50", got)
+	}
+
+	if got, ok := counts["other"]; !ok || got != nil {
+		t.Errorf("repeat_count of other = %v, want none", got)
+	}
+}
+
+func TestDedupWithForceLevel(t *testing.T) {
+	l, buf := newTestLogger(t, "info", Config{Dedup: &DedupConfig{Window: time.Hour}})
+
+	ctx := WithForceLevel(WithCtx(context.Background(), l), zap.DebugLevel)
+	for i := 0; i < 3; i++ {
+		FromCtx(ctx).Debug("forced")
+	}
+	l.Debug("dropped")
+	Sync()
+
+	entries := decodeEntries(t, buf.String())
+	if len(entries) != 1 {
+		t.Fatalf("got %d entries, want 1: %s", len(entries), buf.String())
+	}
+
+	if entries[0]["msg"] != "forced" || entries[0]["level"] != "debug" || entries[0]["repeat_count"] != float64(3) {
+		t.Errorf("got %v, want the forced debug entry repeated 3 times", entries[0])
+	}
+}
@@ -0,0 +1,112 @@
+package logger
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+const defaultReportInterval = time.Minute
+
+// dropReporter counts the entries dropped by sampling and reports them
+// with a warning written to core every interval, and on Sync and Close.
+type dropReporter struct {
+	core zapcore.Core
+
+	mu    sync.Mutex
+	count int
+
+	stop chan struct{}
+	done chan struct{}
+	once sync.Once
+}
+
+// startDropReporter starts reporting the entries dropped by the sampling
+// configured by cfg, writing its warnings to core.
+func startDropReporter(core zapcore.Core, cfg SamplingConfig) *dropReporter {
+	interval := cfg.ReportInterval
+	if interval <= 0 {
+		interval = defaultReportInterval
+	}
+
+	r := &dropReporter{core: core, stop: make(chan struct{}), done: make(chan struct{})}
+
+	go func() {
+		defer close(r.done)
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				r.report()
+			case <-r.stop:
+				return
+			}
+		}
+	}()
+
+	return r
+}
+
+// hook is the zapcore.SamplerHook counting dropped entries.
+func (r *dropReporter) hook(_ zapcore.Entry, dec zapcore.SamplingDecision) {
+	if dec&zapcore.LogDropped == 0 {
+		return
+	}
+
+	r.mu.Lock()
+	r.count++
+	r.mu.Unlock()
+}
+
+// report writes a warning counting the entries dropped since the last
+// one, if any were.
+func (r *dropReporter) report() {
+	r.mu.Lock()
+	n := r.count
+	r.count = 0
+	r.mu.Unlock()
+
+	if n == 0 {
+		return
+	}
+
+	ent := zapcore.Entry{
+		Level:   zapcore.WarnLevel,
+		Time:    time.Now(),
+		Message: fmt.Sprintf("dropped %d log entries due to sampling", n),
+	}
+	if ce := r.core.Check(ent, nil); ce != nil {
+		ce.Write(zap.Int("dropped", n))
+	}
+}
+
+// Close stops the reporter and reports the entries dropped since the
+// last warning.
+func (r *dropReporter) Close() error {
+	r.once.Do(func() { close(r.stop) })
+	<-r.done
+	r.report()
+	return nil
+}
+
+// reportingCore is a zapcore.Core reporting the entries dropped by the
+// sampling it wraps before syncing.
+type reportingCore struct {
+	zapcore.Core
+	reporter *dropReporter
+}
+
+func (c *reportingCore) With(fields []zapcore.Field) zapcore.Core {
+	return &reportingCore{Core: c.Core.With(fields), reporter: c.reporter}
+}
+
+func (c *reportingCore) Sync() error {
+	c.reporter.report()
+	return c.Core.Sync()
+}
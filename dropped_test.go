@@ -0,0 +1,50 @@
+package logger
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDropReporterInterval(t *testing.T) {
+	l, buf := newTestLogger(t, "info", Config{
+		Sampling: &SamplingConfig{First: 1, Thereafter: 1000, ReportInterval: 10 * time.Millisecond},
+	})
+
+	for i := 0; i < 10; i++ {
+		l.Info("burst")
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for !strings.Contains(buf.String(), "dropped 9 log entries due to sampling") {
+		if time.Now().After(deadline) {
+			t.Fatalf("no summary line in %q", buf.String())
+		}
+
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestDropReporterSync(t *testing.T) {
+	l, buf := newTestLogger(t, "info", Config{
+		Sampling: &SamplingConfig{First: 1, Thereafter: 1000, ReportInterval: time.Hour},
+	})
+
+	l.Info("once")
+	Sync()
+
+	if strings.Contains(buf.String(), "dropped") {
+		t.Errorf("summary line written without drops: %q", buf.String())
+	}
+
+	for i := 0; i < 5; i++ {
+		l.Info("burst")
+	}
+	Sync()
+
+	entries := decodeEntries(t, buf.String())
+	last := entries[len(entries)-1]
+	if last["msg"] != "dropped 4 log entries due to sampling" || last["dropped"] != float64(4) || last["level"] != "warn" {
+		t.Errorf("got %v, want a warning counting 4 dropped entries", last)
+	}
+}
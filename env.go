@@ -0,0 +1,84 @@
+package logger
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+
+	"go.uber.org/multierr"
+	"go.uber.org/zap"
+)
+
+// GetFromEnv is like Get but reads its configuration from the
+// environment:
+//
+//	LOG_PATH          the log file
+//	LOG_LEVEL         the level, defaults to info
+//	LOG_FORMAT        json, console or logfmt
+//	LOG_MAX_SIZE_MB   see Config.MaxSizeMB
+//	LOG_MAX_BACKUPS   see Config.MaxBackups
+//	LOG_MAX_AGE_DAYS  see Config.MaxAgeDays
+//	LOG_COMPRESS      true or false, see Config.Compress
+//
+// Unset or invalid variables keep their defaults.
+func GetFromEnv() *zap.Logger {
+	l, _ := GetFromEnvE()
+	return l
+}
+
+// GetFromEnvE is like GetFromEnv but also returns the error encountered
+// while initializing the logger, such as a variable failing to parse.
+func GetFromEnvE() (*zap.Logger, error) {
+	c, envErr := configFromEnv()
+
+	l, err := GetE(c.Path, c.Level, c)
+	return l, multierr.Append(envErr, err)
+}
+
+// configFromEnv reads the Config from the environment.
+func configFromEnv() (c Config, err error) {
+	c.Path = os.Getenv("LOG_PATH")
+	c.Level = os.Getenv("LOG_LEVEL")
+
+	if v := os.Getenv("LOG_FORMAT"); v != "" {
+		if formatErr := checkFormat(v); formatErr != nil {
+			err = fmt.Errorf("invalid LOG_FORMAT: %w", formatErr)
+		} else {
+			c.Format = v
+		}
+	}
+
+	err = multierr.Append(err, multierr.Combine(
+		envInt("LOG_MAX_SIZE_MB", &c.MaxSizeMB),
+		envInt("LOG_MAX_BACKUPS", &c.MaxBackups),
+		envInt("LOG_MAX_AGE_DAYS", &c.MaxAgeDays),
+	))
+
+	if v := os.Getenv("LOG_COMPRESS"); v != "" {
+		compress, parseErr := strconv.ParseBool(v)
+		if parseErr != nil {
+			err = multierr.Append(err, fmt.Errorf("invalid LOG_COMPRESS: %w", parseErr))
+		} else {
+			c.Compress = &compress
+		}
+	}
+
+	return c, err
+}
+
+// envInt sets dst to the integer value of the environment variable key,
+// if set.
+func envInt(key string, dst *int) error {
+	v := os.Getenv(key)
+	if v == "" {
+		return nil
+	}
+
+	n, err := strconv.Atoi(v)
+	if err != nil {
+		return fmt.Errorf("invalid %s: %w", key, err)
+	}
+
+	*dst = n
+	return nil
+}
@@ -0,0 +1,64 @@
+package logger
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestConfigFromEnv(t *testing.T) {
+	t.Setenv("LOG_PATH", "/var/log/app.log")
+	t.Setenv("LOG_LEVEL", "debug")
+	t.Setenv("LOG_FORMAT", "logfmt")
+	t.Setenv("LOG_MAX_SIZE_MB", "10")
+	t.Setenv("LOG_MAX_BACKUPS", "5")
+	t.Setenv("LOG_MAX_AGE_DAYS", "7")
+	t.Setenv("LOG_COMPRESS", "false")
+
+	c, err := configFromEnv()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if c.Path != "/var/log/app.log" || c.Level != "debug" || c.Format != FormatLogfmt {
+		t.Errorf("Path, Level, Format = %q, %q, %q", c.Path, c.Level, c.Format)
+	}
+
+	if c.MaxSizeMB != 10 || c.MaxBackups != 5 || c.MaxAgeDays != 7 {
+		t.Errorf("MaxSizeMB, MaxBackups, MaxAgeDays = %d, %d, %d", c.MaxSizeMB, c.MaxBackups, c.MaxAgeDays)
+	}
+
+	if c.Compress == nil || *c.Compress {
+		t.Errorf("Compress = %v, want false", c.Compress)
+	}
+}
+
+func TestConfigFromEnvInvalid(t *testing.T) {
+	t.Setenv("LOG_MAX_SIZE_MB", "big")
+	t.Setenv("LOG_COMPRESS", "maybe")
+	t.Setenv("LOG_FORMAT", "yaml")
+
+	c, err := configFromEnv()
+	if err == nil || !strings.Contains(err.Error(), "LOG_FORMAT") {
+		t.Errorf("configFromEnv() error = %v, want one naming LOG_FORMAT", err)
+	}
+
+	if c.MaxSizeMB != 0 || c.Compress != nil || c.Format != "" {
+		t.Errorf("invalid variables changed the defaults: %+v", c)
+	}
+}
+
+func TestGetFromEnvEInvalidFormat(t *testing.T) {
+	resetForTest(t)
+	t.Setenv("LOG_PATH", filepath.Join(t.TempDir(), "app.log"))
+	t.Setenv("LOG_FORMAT", "yaml")
+
+	l, err := GetFromEnvE()
+	if err == nil {
+		t.Error("GetFromEnvE returned no error for LOG_FORMAT=yaml")
+	}
+
+	if l == nil {
+		t.Error("GetFromEnvE returned no logger")
+	}
+}
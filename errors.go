@@ -0,0 +1,52 @@
+package logger
+
+import (
+	"errors"
+	"fmt"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// LogError returns a field logging err under the error key. When err
+// wraps other errors their messages, from the outermost down, are added
+// as the error_chain array, and when err carries a stack trace printed
+// by %+v, as the errors of github.com/pkg/errors do, it is added as
+// error_stack. A nil err is skipped.
+func LogError(err error) zapcore.Field {
+	if err == nil {
+		return zap.Skip()
+	}
+
+	return zap.Inline(errorFields{err})
+}
+
+// errorFields marshals the fields logged by LogError.
+type errorFields struct {
+	err error
+}
+
+func (e errorFields) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	msg := e.err.Error()
+	enc.AddString("error", msg)
+
+	if errors.Unwrap(e.err) != nil {
+		_ = enc.AddArray("error_chain", zapcore.ArrayMarshalerFunc(
+			func(arr zapcore.ArrayEncoder) error {
+				for err := e.err; err != nil; err = errors.Unwrap(err) {
+					arr.AppendString(err.Error())
+				}
+
+				return nil
+			},
+		))
+	}
+
+	if _, ok := e.err.(fmt.Formatter); ok {
+		if verbose := fmt.Sprintf("%+v", e.err); verbose != msg {
+			enc.AddString("error_stack", verbose)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,51 @@
+package logger
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// encodeField returns what f adds to an object.
+func encodeField(f zapcore.Field) map[string]interface{} {
+	enc := zapcore.NewMapObjectEncoder()
+	f.AddTo(enc)
+	return enc.Fields
+}
+
+func TestLogError(t *testing.T) {
+	t.Run("plain", func(t *testing.T) {
+		got := encodeField(LogError(errors.New("boom")))
+
+		if got["error"] != "boom" {
+			t.Errorf("error = %v, want boom", got["error"])
+		}
+
+		if _, ok := got["error_chain"]; ok {
+			t.Error("error_chain logged for an error wrapping none")
+		}
+	})
+
+	t.Run("wrapped", func(t *testing.T) {
+		err := fmt.Errorf("query: %w", fmt.Errorf("connect: %w", errors.New("refused")))
+		got := encodeField(LogError(err))
+
+		if got["error"] != "query: connect: refused" {
+			t.Errorf("error = %v", got["error"])
+		}
+
+		want := []interface{}{"query: connect: refused", "connect: refused", "refused"}
+		if chain := fmt.Sprint(got["error_chain"]); chain != fmt.Sprint(want) {
+			t.Errorf("error_chain = %s, want %s", chain, fmt.Sprint(want))
+		}
+	})
+
+	t.Run("nil", func(t *testing.T) {
+		if f := LogError(nil); f != zap.Skip() {
+			t.Errorf("LogError(nil) = %v, want zap.Skip()", f)
+		}
+	})
+}
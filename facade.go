@@ -0,0 +1,99 @@
+package logger
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// Debug logs msg at debug level with the Logger of ctx, adding the
+// fields stored in ctx, such as the user id set by WithUserID.
+func Debug(ctx context.Context, msg string, fields ...zap.Field) {
+	logCtx(ctx, zap.DebugLevel, msg, fields)
+}
+
+// Info logs msg at info level, see Debug.
+func Info(ctx context.Context, msg string, fields ...zap.Field) {
+	logCtx(ctx, zap.InfoLevel, msg, fields)
+}
+
+// Warn logs msg at warn level, see Debug.
+func Warn(ctx context.Context, msg string, fields ...zap.Field) {
+	logCtx(ctx, zap.WarnLevel, msg, fields)
+}
+
+// Error logs msg at error level, see Debug.
+func Error(ctx context.Context, msg string, fields ...zap.Field) {
+	logCtx(ctx, zap.ErrorLevel, msg, fields)
+}
+
+// Infof formats its arguments as fmt.Sprintf does and logs the result
+// at info level, see Debug.
+func Infof(ctx context.Context, format string, args ...interface{}) {
+	logfCtx(ctx, zap.InfoLevel, format, args)
+}
+
+// Errorf formats its arguments as fmt.Sprintf does and logs the result
+// at error level, see Debug.
+func Errorf(ctx context.Context, format string, args ...interface{}) {
+	logfCtx(ctx, zap.ErrorLevel, format, args)
+}
+
+// logfCtx is like logCtx but only formats the message when level is
+// enabled.
+func logfCtx(ctx context.Context, level zapcore.Level, format string, args []interface{}) {
+	l := FromCtx(ctx).WithOptions(zap.AddCallerSkip(2))
+	if !l.Core().Enabled(level) {
+		return
+	}
+
+	if ce := l.Check(level, fmt.Sprintf(format, args...)); ce != nil {
+		ce.Write(contextFields(ctx)...)
+	}
+}
+
+// logCtx logs msg at level with the Logger of ctx and the context fields.
+func logCtx(ctx context.Context, level zapcore.Level, msg string, fields []zap.Field) {
+	l := FromCtx(ctx).WithOptions(zap.AddCallerSkip(2))
+	if ce := l.Check(level, msg); ce != nil {
+		ce.Write(append(contextFields(ctx), fields...)...)
+	}
+}
+
+var (
+	extractorsMu sync.RWMutex
+	extractors   []func(context.Context) []zap.Field
+)
+
+// RegisterContextExtractor registers extract to add the fields it
+// returns for the context to every entry logged by Debug, Info, Warn and
+// Error, e.g. a tenant id. It is typically called from init.
+func RegisterContextExtractor(extract func(context.Context) []zap.Field) {
+	extractorsMu.Lock()
+	defer extractorsMu.Unlock()
+
+	extractors = append(extractors, extract)
+}
+
+// contextFields returns the field for the user id stored in ctx by
+// WithUserID, followed by those of the registered extractors. The
+// request id is not among them: WithRequestID adds it to the Logger of
+// ctx.
+func contextFields(ctx context.Context) []zap.Field {
+	var fields []zap.Field
+	if id, ok := ctx.Value(userIDKey{}).(string); ok {
+		fields = append(fields, zap.String("user_id", id))
+	}
+
+	extractorsMu.RLock()
+	defer extractorsMu.RUnlock()
+
+	for _, extract := range extractors {
+		fields = append(fields, extract(ctx)...)
+	}
+
+	return fields
+}
@@ -0,0 +1,82 @@
+package logger
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"go.uber.org/zap"
+)
+
+func TestFacadeContextFields(t *testing.T) {
+	_, buf := newTestLogger(t, "info", Config{})
+
+	ctx := WithRequestID(WithUserID(context.Background(), "u1"), "req-1")
+	Info(ctx, "served", zap.Int("items", 3))
+
+	entry := decodeEntries(t, buf.String())[0]
+	if entry["user_id"] != "u1" || entry["request_id"] != "req-1" || entry["items"] != float64(3) {
+		t.Errorf("got %v, want the context and call fields", entry)
+	}
+}
+
+func TestRegisterContextExtractor(t *testing.T) {
+	_, buf := newTestLogger(t, "info", Config{})
+
+	registered := extractors
+	t.Cleanup(func() { extractors = registered })
+
+	type tenantKey struct{}
+	RegisterContextExtractor(func(ctx context.Context) []zap.Field {
+		if tenant, ok := ctx.Value(tenantKey{}).(string); ok {
+			return []zap.Field{zap.String("tenant", tenant)}
+		}
+		return nil
+	})
+	RegisterContextExtractor(func(context.Context) []zap.Field {
+		return []zap.Field{zap.String("region", "eu"), zap.Int("shard", 2)}
+	})
+
+	Info(context.WithValue(context.Background(), tenantKey{}, "acme"), "tenant")
+	Info(context.Background(), "no tenant")
+
+	entries := decodeEntries(t, buf.String())
+	if len(entries) != 2 {
+		t.Fatalf("got %d entries, want 2", len(entries))
+	}
+
+	if e := entries[0]; e["tenant"] != "acme" || e["region"] != "eu" || e["shard"] != float64(2) {
+		t.Errorf("got %v, want the fields of both extractors", e)
+	}
+
+	if e := entries[1]; e["region"] != "eu" || e["tenant"] != nil {
+		t.Errorf("got %v, want only the fields of the second extractor", e)
+	}
+}
+
+func TestInfofErrorf(t *testing.T) {
+	_, buf := newTestLogger(t, "warn", Config{AddCaller: true})
+
+	ctx := WithUserID(context.Background(), "u1")
+	Infof(ctx, "skipped %d", 1)
+	Errorf(ctx, "failed %d of %s", 2, "jobs")
+	SetLevel(zap.InfoLevel)
+	Infof(ctx, "served %.1f%%", 99.5)
+
+	entries := decodeEntries(t, buf.String())
+	if len(entries) != 2 {
+		t.Fatalf("got %d entries, want 2", len(entries))
+	}
+
+	if e := entries[0]; e["msg"] != "failed 2 of jobs" || e["level"] != "error" || e["user_id"] != "u1" {
+		t.Errorf("got %v, want the formatted error", e)
+	}
+
+	if e := entries[1]; e["msg"] != "served 99.5%" || e["level"] != "info" {
+		t.Errorf("got %v, want the formatted info entry", e)
+	}
+
+	if caller, _ := entries[0]["caller"].(string); !strings.Contains(caller, "facade_test.go:") {
+		t.Errorf("caller = %q, want the test", caller)
+	}
+}
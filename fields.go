@@ -0,0 +1,27 @@
+package logger
+
+import (
+	"sort"
+
+	"go.uber.org/zap"
+)
+
+// MapFields converts m into fields sorted by key. zap.Any picks the
+// typed field matching each value, e.g. zap.String for a string or
+// zap.Int for an int, and reflects values of other types such as
+// nested maps.
+func MapFields(m map[string]interface{}) []zap.Field {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+
+	sort.Strings(keys)
+
+	fields := make([]zap.Field, 0, len(keys))
+	for _, k := range keys {
+		fields = append(fields, zap.Any(k, m[k]))
+	}
+
+	return fields
+}
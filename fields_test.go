@@ -0,0 +1,48 @@
+package logger
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"go.uber.org/zap/zapcore"
+)
+
+func TestMapFields(t *testing.T) {
+	fields := MapFields(map[string]interface{}{
+		"name":     "alice",
+		"count":    3,
+		"ratio":    0.5,
+		"active":   true,
+		"timeout":  time.Second,
+		"err":      errors.New("boom"),
+		"nested":   map[string]interface{}{"id": 7},
+		"at":       time.Unix(0, 0),
+		"attempts": int64(2),
+	})
+
+	want := []struct {
+		key string
+		typ zapcore.FieldType
+	}{
+		{"active", zapcore.BoolType},
+		{"at", zapcore.TimeType},
+		{"attempts", zapcore.Int64Type},
+		{"count", zapcore.Int64Type},
+		{"err", zapcore.ErrorType},
+		{"name", zapcore.StringType},
+		{"nested", zapcore.ReflectType},
+		{"ratio", zapcore.Float64Type},
+		{"timeout", zapcore.DurationType},
+	}
+
+	if len(fields) != len(want) {
+		t.Fatalf("got %d fields, want %d", len(fields), len(want))
+	}
+
+	for i, w := range want {
+		if fields[i].Key != w.key || fields[i].Type != w.typ {
+			t.Errorf("field %d = %s of type %d, want %s of type %d", i, fields[i].Key, fields[i].Type, w.key, w.typ)
+		}
+	}
+}
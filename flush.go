@@ -0,0 +1,45 @@
+package logger
+
+import (
+	"sync"
+	"time"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// flusher syncs a core periodically until it is closed.
+type flusher struct {
+	stop chan struct{}
+	done chan struct{}
+	once sync.Once
+}
+
+// startFlusher starts syncing core every interval.
+func startFlusher(core zapcore.Core, interval time.Duration) *flusher {
+	f := &flusher{stop: make(chan struct{}), done: make(chan struct{})}
+
+	go func() {
+		defer close(f.done)
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				_ = core.Sync()
+			case <-f.stop:
+				return
+			}
+		}
+	}()
+
+	return f
+}
+
+// Close stops the flusher and waits for a sync in progress to return.
+func (f *flusher) Close() error {
+	f.once.Do(func() { close(f.stop) })
+	<-f.done
+	return nil
+}
@@ -0,0 +1,51 @@
+package logger
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFlushInterval(t *testing.T) {
+	resetForTest(t)
+
+	path := filepath.Join(t.TempDir(), "app.log")
+	l := Get(path, "info", Config{
+		Async:         &AsyncConfig{BufferSize: 1 << 20, FlushInterval: time.Hour},
+		FlushInterval: 10 * time.Millisecond,
+		Quiet:         true,
+	})
+
+	l.Info("buffered")
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		if b, _ := os.ReadFile(path); strings.Contains(string(b), "buffered") {
+			break
+		}
+
+		if time.Now().After(deadline) {
+			t.Fatal("entry not flushed without Sync")
+		}
+
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	if err := Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestFlusherCloseTwice(t *testing.T) {
+	f := startFlusher(nil, time.Hour)
+
+	if err := f.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := f.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
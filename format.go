@@ -0,0 +1,152 @@
+package logger
+
+import (
+	"fmt"
+	"sync/atomic"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// SetFormat switches the console and the log file of the logger returned
+// by Get to format, FormatJSON, FormatConsole or FormatLogfmt, without
+// reopening them or changing the level, e.g. to read the logs during an
+// incident. The other destinations keep their format.
+func SetFormat(format string) error {
+	if err := checkFormat(format); err != nil {
+		return err
+	}
+
+	if defaultOutput != nil && defaultOutput.format != nil {
+		defaultOutput.format.set(format)
+	}
+
+	return nil
+}
+
+// checkFormat returns an error unless format is FormatJSON, FormatConsole
+// or FormatLogfmt.
+func checkFormat(format string) error {
+	switch format {
+	case FormatJSON, FormatConsole, FormatLogfmt:
+		return nil
+	default:
+		return fmt.Errorf("unknown log format %q", format)
+	}
+}
+
+// formatSwitch holds the format selected by SetFormat.
+type formatSwitch struct {
+	v atomic.Pointer[formatState]
+}
+
+// formatState is a format selected by SetFormat, the empty format
+// standing for the configured ones. A new formatState is stored on every
+// change so that its address tells the cores to rebuild their encoders.
+type formatState struct {
+	format string
+}
+
+func newFormatSwitch() *formatSwitch {
+	s := &formatSwitch{}
+	s.set("")
+	return s
+}
+
+func (s *formatSwitch) set(format string) {
+	s.v.Store(&formatState{format: format})
+}
+
+// switchCore is a zapcore.Core like zapcore.NewCore whose encoder is
+// rebuilt, with the fields added by With, when the format changes. As
+// with zapcore.NewCore, With encodes the fields straight away; they are
+// only kept to be encoded again by the rebuild, which reads their values
+// anew, e.g. calling an ObjectMarshaler again.
+type switchCore struct {
+	zapcore.LevelEnabler
+	format *formatSwitch
+
+	// newEncoder returns the encoder for a format, the configured one
+	// for the empty format.
+	newEncoder func(format string) zapcore.Encoder
+	out        zapcore.WriteSyncer
+	fields     []zapcore.Field
+
+	enc atomic.Pointer[switchEncoder]
+}
+
+// switchEncoder is the encoder of a switchCore for a formatState.
+type switchEncoder struct {
+	state *formatState
+	enc   zapcore.Encoder
+}
+
+func newSwitchCore(format *formatSwitch, newEncoder func(string) zapcore.Encoder, out zapcore.WriteSyncer, enab zapcore.LevelEnabler) zapcore.Core {
+	return &switchCore{LevelEnabler: enab, format: format, newEncoder: newEncoder, out: out}
+}
+
+// encoder returns the encoder for the current format.
+func (c *switchCore) encoder() *switchEncoder {
+	state := c.format.v.Load()
+	if e := c.enc.Load(); e != nil && e.state == state {
+		return e
+	}
+
+	enc := c.newEncoder(state.format)
+	for _, f := range c.fields {
+		f.AddTo(enc)
+	}
+
+	e := &switchEncoder{state: state, enc: enc}
+	c.enc.Store(e)
+	return e
+}
+
+func (c *switchCore) With(fields []zapcore.Field) zapcore.Core {
+	e := c.encoder()
+	enc := e.enc.Clone()
+	for _, f := range fields {
+		f.AddTo(enc)
+	}
+
+	clone := &switchCore{
+		LevelEnabler: c.LevelEnabler,
+		format:       c.format,
+		newEncoder:   c.newEncoder,
+		out:          c.out,
+		fields:       append(append([]zapcore.Field(nil), c.fields...), fields...),
+	}
+	clone.enc.Store(&switchEncoder{state: e.state, enc: enc})
+	return clone
+}
+
+func (c *switchCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+
+	return ce
+}
+
+func (c *switchCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	buf, err := c.encoder().enc.EncodeEntry(ent, fields)
+	if err != nil {
+		return err
+	}
+
+	_, err = c.out.Write(buf.Bytes())
+	buf.Free()
+	if err != nil {
+		return err
+	}
+
+	// Entries that may end the process are flushed straight away
+	if ent.Level > zapcore.ErrorLevel {
+		_ = c.Sync()
+	}
+
+	return nil
+}
+
+func (c *switchCore) Sync() error {
+	return c.out.Sync()
+}
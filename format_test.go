@@ -0,0 +1,69 @@
+package logger
+
+import (
+	"strings"
+	"testing"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestSetFormat(t *testing.T) {
+	l, buf := newTestLogger(t, "info", Config{})
+	child := l.With(zap.String("user", "alice"))
+
+	child.Info("as json")
+	if err := SetFormat(FormatLogfmt); err != nil {
+		t.Fatal(err)
+	}
+	child.Info("as logfmt")
+	if err := SetFormat(FormatConsole); err != nil {
+		t.Fatal(err)
+	}
+	child.Info("as console")
+
+	if err := SetFormat("xml"); err == nil {
+		t.Error("SetFormat accepted an unknown format")
+	}
+
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("got %d lines, want 3: %q", len(lines), buf.String())
+	}
+
+	if entries := decodeEntries(t, lines[0]); entries[0]["user"] != "alice" {
+		t.Errorf("got %q, want a JSON entry with the user", lines[0])
+	}
+
+	if !strings.Contains(lines[1], `msg="as logfmt"`) || !strings.Contains(lines[1], "user=alice") {
+		t.Errorf("got %q, want a logfmt entry with the user", lines[1])
+	}
+
+	if !strings.Contains(lines[2], "\tinfo\tas console\t") || !strings.Contains(lines[2], `"user": "alice"`) {
+		t.Errorf("got %q, want a console entry with the user", lines[2])
+	}
+}
+
+// stateMarshaler encodes the current value of state.
+type stateMarshaler struct {
+	state *string
+}
+
+func (m stateMarshaler) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	enc.AddString("state", *m.state)
+	return nil
+}
+
+func TestSwitchCoreWithEncodesStraightAway(t *testing.T) {
+	l, buf := newTestLogger(t, "info", Config{})
+
+	state := "before"
+	child := l.With(zap.Object("job", stateMarshaler{&state}))
+	state = "after"
+
+	child.Info("encoded")
+
+	if !strings.Contains(buf.String(), `"job":{"state":"before"}`) {
+		t.Errorf("got %q, want the field as it was when With was called", buf.String())
+	}
+}
@@ -0,0 +1,75 @@
+// Package grpcmw provides gRPC server interceptors that seed the context
+// logger of every call. It lives in its own package so that importing
+// the logger does not pull in gRPC.
+package grpcmw
+
+import (
+	"context"
+
+	logger "github.com/Lanquill/go-logger"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// requestIDKey is the metadata key the request id is read from.
+const requestIDKey = "x-request-id"
+
+// UnaryServerInterceptor returns an interceptor that passes handlers a
+// context whose logger carries the grpc_method and request_id fields.
+// The request id is taken from the x-request-id metadata, or generated.
+func UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(
+		ctx context.Context,
+		req any,
+		info *grpc.UnaryServerInfo,
+		handler grpc.UnaryHandler,
+	) (any, error) {
+		return handler(withLogger(ctx, info.FullMethod), req)
+	}
+}
+
+// StreamServerInterceptor is the streaming counterpart of
+// UnaryServerInterceptor.
+func StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(
+		srv any,
+		ss grpc.ServerStream,
+		info *grpc.StreamServerInfo,
+		handler grpc.StreamHandler,
+	) error {
+		return handler(srv, &serverStream{
+			ServerStream: ss,
+			ctx:          withLogger(ss.Context(), info.FullMethod),
+		})
+	}
+}
+
+// serverStream overrides the context of a grpc.ServerStream.
+type serverStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *serverStream) Context() context.Context {
+	return s.ctx
+}
+
+// withLogger returns a copy of ctx carrying the request id and a logger
+// annotated with method and the request id.
+func withLogger(ctx context.Context, method string) context.Context {
+	ctx = logger.WithRequestID(ctx, requestID(ctx))
+	return logger.WithFields(ctx, zap.String("grpc_method", method))
+}
+
+// requestID returns the request id from the incoming metadata of ctx,
+// or a new one if there is none.
+func requestID(ctx context.Context) string {
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if ids := md.Get(requestIDKey); len(ids) > 0 && ids[0] != "" {
+			return ids[0]
+		}
+	}
+
+	return logger.NewRequestID()
+}
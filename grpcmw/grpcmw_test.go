@@ -0,0 +1,42 @@
+package grpcmw
+
+import (
+	"context"
+	"testing"
+
+	logger "github.com/Lanquill/go-logger"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+func TestUnaryServerInterceptor(t *testing.T) {
+	l, logs := logger.NewObserved(zap.InfoLevel)
+	logger.SetDefault(l)
+	t.Cleanup(logger.Reset)
+
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(requestIDKey, "req-1"))
+	info := &grpc.UnaryServerInfo{FullMethod: "/pkg.Service/Method"}
+	handler := func(ctx context.Context, req any) (any, error) {
+		logger.FromCtx(ctx).Info("handled")
+		return nil, nil
+	}
+
+	if _, err := UnaryServerInterceptor()(ctx, nil, info, handler); err != nil {
+		t.Fatal(err)
+	}
+
+	entries := logs.All()
+	if len(entries) != 1 {
+		t.Fatalf("got %d entries, want 1", len(entries))
+	}
+
+	fields := entries[0].ContextMap()
+	if fields["grpc_method"] != "/pkg.Service/Method" {
+		t.Errorf("grpc_method = %v", fields["grpc_method"])
+	}
+
+	if fields["request_id"] != "req-1" {
+		t.Errorf("request_id = %v, want req-1", fields["request_id"])
+	}
+}
@@ -0,0 +1,36 @@
+package logger
+
+import (
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// Logger is the subset of *zap.Logger most code needs, so that it can
+// depend on an interface and be given a fake in tests.
+type Logger interface {
+	Debug(msg string, fields ...zapcore.Field)
+	Info(msg string, fields ...zapcore.Field)
+	Warn(msg string, fields ...zapcore.Field)
+	Error(msg string, fields ...zapcore.Field)
+	With(fields ...zapcore.Field) Logger
+	Sync() error
+}
+
+// GetLogger is like Get but returns the logger as a Logger.
+func GetLogger(logPath, logLevel string, cfg ...Config) Logger {
+	return AsLogger(Get(logPath, logLevel, cfg...))
+}
+
+// AsLogger adapts l to the Logger interface.
+func AsLogger(l *zap.Logger) Logger {
+	return zapLogger{l}
+}
+
+// zapLogger implements Logger with a *zap.Logger.
+type zapLogger struct {
+	*zap.Logger
+}
+
+func (l zapLogger) With(fields ...zapcore.Field) Logger {
+	return zapLogger{l.Logger.With(fields...)}
+}
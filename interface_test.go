@@ -0,0 +1,45 @@
+package logger
+
+import (
+	"testing"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// fakeLogger is a Logger recording the messages logged through it.
+type fakeLogger struct {
+	messages *[]string
+}
+
+func (l fakeLogger) record(msg string)                    { *l.messages = append(*l.messages, msg) }
+func (l fakeLogger) Debug(msg string, _ ...zapcore.Field) { l.record(msg) }
+func (l fakeLogger) Info(msg string, _ ...zapcore.Field)  { l.record(msg) }
+func (l fakeLogger) Warn(msg string, _ ...zapcore.Field)  { l.record(msg) }
+func (l fakeLogger) Error(msg string, _ ...zapcore.Field) { l.record(msg) }
+func (l fakeLogger) With(_ ...zapcore.Field) Logger       { return l }
+func (l fakeLogger) Sync() error                          { return nil }
+
+// process stands for code depending on the Logger interface.
+func process(log Logger) {
+	log.With(zap.String("step", "load")).Info("processing")
+}
+
+func TestLoggerInterface(t *testing.T) {
+	var messages []string
+	process(fakeLogger{messages: &messages})
+
+	if len(messages) != 1 || messages[0] != "processing" {
+		t.Errorf("messages = %v, want [processing]", messages)
+	}
+}
+
+func TestAsLogger(t *testing.T) {
+	l, logs := NewObserved(zap.InfoLevel)
+	process(AsLogger(l))
+
+	entries := logs.All()
+	if len(entries) != 1 || entries[0].ContextMap()["step"] != "load" {
+		t.Errorf("entries = %v, want one with step=load", entries)
+	}
+}
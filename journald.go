@@ -0,0 +1,185 @@
+package logger
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"go.uber.org/zap/buffer"
+	"go.uber.org/zap/zapcore"
+)
+
+const defaultJournaldSocket = "/run/systemd/journal/socket"
+
+var journaldPool = buffer.NewPool()
+
+// JournaldConfig configures the journald destination.
+type JournaldConfig struct {
+	// SocketPath is the journald socket. Defaults to
+	// /run/systemd/journal/socket.
+	SocketPath string
+
+	// Identifier is sent as SYSLOG_IDENTIFIER. Defaults to the program
+	// name.
+	Identifier string
+}
+
+// journaldPriorities maps levels to the syslog priorities journald uses.
+var journaldPriorities = map[zapcore.Level]int{
+	zapcore.DebugLevel:  7,
+	zapcore.InfoLevel:   6,
+	zapcore.WarnLevel:   4,
+	zapcore.ErrorLevel:  3,
+	zapcore.DPanicLevel: 2,
+	zapcore.PanicLevel:  2,
+	zapcore.FatalLevel:  0,
+}
+
+// journaldCore is a zapcore.Core sending every entry to journald as one
+// datagram of the native protocol, its fields becoming journal fields.
+type journaldCore struct {
+	zapcore.LevelEnabler
+	identifier string
+	fields     []zapcore.Field
+	w          io.Writer
+}
+
+func newJournaldCore(cfg JournaldConfig, w io.Writer, enab zapcore.LevelEnabler) zapcore.Core {
+	identifier := cfg.Identifier
+	if identifier == "" {
+		identifier = filepath.Base(os.Args[0])
+	}
+
+	return &journaldCore{LevelEnabler: enab, identifier: identifier, w: w}
+}
+
+func (c *journaldCore) With(fields []zapcore.Field) zapcore.Core {
+	clone := *c
+	clone.fields = append(append([]zapcore.Field(nil), c.fields...), fields...)
+	return &clone
+}
+
+func (c *journaldCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+
+	return ce
+}
+
+func (c *journaldCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	buf := journaldPool.Get()
+	defer buf.Free()
+
+	appendJournaldField(buf, "MESSAGE", ent.Message)
+	appendJournaldField(buf, "PRIORITY", strconv.Itoa(journaldPriorities[ent.Level]))
+	appendJournaldField(buf, "SYSLOG_IDENTIFIER", c.identifier)
+
+	if ent.LoggerName != "" {
+		appendJournaldField(buf, "LOGGER", ent.LoggerName)
+	}
+
+	if ent.Caller.Defined {
+		appendJournaldField(buf, "CODE_FILE", ent.Caller.File)
+		appendJournaldField(buf, "CODE_LINE", strconv.Itoa(ent.Caller.Line))
+		appendJournaldField(buf, "CODE_FUNC", ent.Caller.Function)
+	}
+
+	if ent.Stack != "" {
+		appendJournaldField(buf, "STACKTRACE", ent.Stack)
+	}
+
+	enc := zapcore.NewMapObjectEncoder()
+	for _, f := range c.fields {
+		f.AddTo(enc)
+	}
+
+	for _, f := range fields {
+		f.AddTo(enc)
+	}
+
+	keys := make([]string, 0, len(enc.Fields))
+	for k := range enc.Fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		appendJournaldField(buf, journaldKey(k), journaldValue(enc.Fields[k]))
+	}
+
+	_, err := c.w.Write(buf.Bytes())
+	return err
+}
+
+func (c *journaldCore) Sync() error {
+	return nil
+}
+
+// appendJournaldField appends a field in the journald native format,
+// KEY=value on a line, or for values spanning several lines the key on
+// a line, followed by the length of the value as a little endian uint64,
+// the value and a newline.
+func appendJournaldField(buf *buffer.Buffer, key, value string) {
+	buf.AppendString(key)
+	if !strings.Contains(value, "\n") {
+		buf.AppendByte('=')
+		buf.AppendString(value)
+		buf.AppendByte('\n')
+		return
+	}
+
+	buf.AppendByte('\n')
+
+	var size [8]byte
+	binary.LittleEndian.PutUint64(size[:], uint64(len(value)))
+	_, _ = buf.Write(size[:])
+
+	buf.AppendString(value)
+	buf.AppendByte('\n')
+}
+
+// journaldKey turns key into a valid journal field name: upper case
+// letters, digits and underscores, starting with a letter and at most 64
+// characters long.
+func journaldKey(key string) string {
+	name := []byte(strings.ToUpper(key))
+	for i, b := range name {
+		if (b < 'A' || b > 'Z') && (b < '0' || b > '9') {
+			name[i] = '_'
+		}
+	}
+
+	if len(name) == 0 || name[0] < 'A' || name[0] > 'Z' {
+		name = append([]byte("F_"), name...)
+	}
+
+	if len(name) > 64 {
+		name = name[:64]
+	}
+
+	return string(name)
+}
+
+// journaldValue formats a value of a zapcore.MapObjectEncoder.
+func journaldValue(v interface{}) string {
+	switch v := v.(type) {
+	case string:
+		return v
+	case fmt.Stringer:
+		return v.String()
+	}
+
+	b, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+
+	return string(b)
+}
@@ -0,0 +1,24 @@
+//go:build linux
+
+package logger
+
+import (
+	"fmt"
+	"io"
+	"net"
+)
+
+// dialJournald connects to the journald socket described by cfg.
+func dialJournald(cfg JournaldConfig) (io.WriteCloser, error) {
+	path := cfg.SocketPath
+	if path == "" {
+		path = defaultJournaldSocket
+	}
+
+	conn, err := net.DialUnix("unixgram", nil, &net.UnixAddr{Name: path, Net: "unixgram"})
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to journald: %w", err)
+	}
+
+	return conn, nil
+}
@@ -0,0 +1,67 @@
+//go:build linux
+
+package logger
+
+import (
+	"encoding/binary"
+	"net"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+func TestJournald(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "journal.sock")
+	conn, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: path, Net: "unixgram"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	l, _ := newTestLogger(t, "info", Config{
+		Journald:       &JournaldConfig{SocketPath: path, Identifier: "app"},
+		OmitHostFields: true,
+	})
+
+	l.Warn("hello", zap.String("order.id", "7"), zap.String("detail", "a\nb"))
+
+	if err := conn.SetReadDeadline(time.Now().Add(5 * time.Second)); err != nil {
+		t.Fatal(err)
+	}
+
+	b := make([]byte, 4096)
+	n, err := conn.Read(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := string(b[:n])
+
+	var size [8]byte
+	binary.LittleEndian.PutUint64(size[:], 3)
+
+	for _, want := range []string{
+		"MESSAGE=hello\nPRIORITY=4\nSYSLOG_IDENTIFIER=app\n",
+		"ORDER_ID=7\n",
+		"DETAIL\n" + string(size[:]) + "a\nb\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("datagram %q does not contain %q", got, want)
+		}
+	}
+}
+
+func TestJournaldUnavailable(t *testing.T) {
+	resetForTest(t)
+
+	_, err := GetE("", "info", Config{
+		Journald: &JournaldConfig{SocketPath: filepath.Join(t.TempDir(), "missing.sock")},
+		Writer:   &syncBuffer{},
+		Quiet:    true,
+	})
+	if err == nil {
+		t.Error("GetE returned no error without the journald socket")
+	}
+}
@@ -0,0 +1,13 @@
+//go:build !linux
+
+package logger
+
+import (
+	"errors"
+	"io"
+)
+
+// dialJournald always fails as journald only exists on Linux.
+func dialJournald(JournaldConfig) (io.WriteCloser, error) {
+	return nil, errors.New("journald is only supported on Linux")
+}
@@ -0,0 +1,103 @@
+package logger
+
+import (
+	"net/http"
+	"sync"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// atomicLevel is the level shared by every core built by Get. It can be
+// changed at runtime, concurrently with logging.
+var atomicLevel = zap.NewAtomicLevel()
+
+// SetLevel changes the level of the logger returned by Get.
+func SetLevel(l zapcore.Level) {
+	atomicLevel.SetLevel(l)
+}
+
+// levelOverride is a level set by TemporarilySetLevel until restored.
+type levelOverride struct {
+	level zapcore.Level
+}
+
+var (
+	// levelMu guards overrides and baseLevel.
+	levelMu sync.Mutex
+
+	// overrides are the active TemporarilySetLevel calls, oldest first,
+	// and baseLevel is the level before the first of them.
+	overrides []*levelOverride
+	baseLevel zapcore.Level
+)
+
+// TemporarilySetLevel sets the level of the logger returned by Get to l
+// and returns a function restoring the previous level, e.g.
+//
+//	defer logger.TemporarilySetLevel(zapcore.DebugLevel)()
+//
+// Overlapping calls may restore in any order: the level is that of the
+// most recent call not restored yet, or the level before the first
+// call once all are restored. Calling a restore function again does
+// nothing. SetLevel and LevelHandler ignore the active calls, so the
+// level they set lasts until the next restore.
+func TemporarilySetLevel(l zapcore.Level) (restore func()) {
+	levelMu.Lock()
+	defer levelMu.Unlock()
+
+	if len(overrides) == 0 {
+		baseLevel = atomicLevel.Level()
+	}
+
+	o := &levelOverride{level: l}
+	overrides = append(overrides, o)
+	atomicLevel.SetLevel(l)
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			levelMu.Lock()
+			defer levelMu.Unlock()
+
+			for i := range overrides {
+				if overrides[i] == o {
+					overrides = append(overrides[:i], overrides[i+1:]...)
+					break
+				}
+			}
+
+			if len(overrides) == 0 {
+				atomicLevel.SetLevel(baseLevel)
+			} else {
+				atomicLevel.SetLevel(overrides[len(overrides)-1].level)
+			}
+		})
+	}
+}
+
+// resetLevelOverrides forgets the active TemporarilySetLevel calls.
+func resetLevelOverrides() {
+	levelMu.Lock()
+	defer levelMu.Unlock()
+
+	overrides = nil
+}
+
+// GetLevel returns the current level of the logger returned by Get.
+func GetLevel() zapcore.Level {
+	return atomicLevel.Level()
+}
+
+// LevelHandler returns an HTTP handler that reports the current level on
+// GET and changes it on PUT. It operates on the same level as Get, so a
+// change takes effect immediately on every sink.
+//
+// Both the response and the PUT request body use the JSON format
+//
+//	{"level":"debug"}
+//
+// PUT also accepts a form-encoded body such as level=debug.
+func LevelHandler() http.Handler {
+	return atomicLevel
+}
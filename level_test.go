@@ -0,0 +1,93 @@
+package logger
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"go.uber.org/zap"
+)
+
+func TestSetLevel(t *testing.T) {
+	l, buf := newTestLogger(t, "info", Config{})
+
+	l.Debug("hidden")
+	if strings.Contains(buf.String(), "hidden") {
+		t.Fatal("debug entry written at info level")
+	}
+
+	SetLevel(zap.DebugLevel)
+	l.Debug("shown")
+	if !strings.Contains(buf.String(), "shown") {
+		t.Error("debug entry not written after SetLevel(DebugLevel)")
+	}
+
+	if got := GetLevel(); got != zap.DebugLevel {
+		t.Errorf("GetLevel() = %v, want debug", got)
+	}
+}
+
+func TestTemporarilySetLevel(t *testing.T) {
+	resetForTest(t)
+
+	restoreDebug := TemporarilySetLevel(zap.DebugLevel)
+	restoreError := TemporarilySetLevel(zap.ErrorLevel)
+	if got := GetLevel(); got != zap.ErrorLevel {
+		t.Fatalf("level = %v, want error", got)
+	}
+
+	restoreError()
+	if got := GetLevel(); got != zap.DebugLevel {
+		t.Errorf("level = %v after the inner restore, want debug", got)
+	}
+
+	restoreDebug()
+	if got := GetLevel(); got != zap.InfoLevel {
+		t.Errorf("level = %v after the outer restore, want info", got)
+	}
+
+	restoreError()
+	if got := GetLevel(); got != zap.InfoLevel {
+		t.Errorf("level = %v after restoring twice, want info", got)
+	}
+}
+
+func TestTemporarilySetLevelOutOfOrder(t *testing.T) {
+	resetForTest(t)
+
+	restoreDebug := TemporarilySetLevel(zap.DebugLevel)
+	restoreError := TemporarilySetLevel(zap.ErrorLevel)
+	restoreWarn := TemporarilySetLevel(zap.WarnLevel)
+
+	restoreDebug()
+	if got := GetLevel(); got != zap.WarnLevel {
+		t.Errorf("level = %v after restoring the oldest call, want warn", got)
+	}
+
+	restoreWarn()
+	if got := GetLevel(); got != zap.ErrorLevel {
+		t.Errorf("level = %v after restoring the newest call, want error", got)
+	}
+
+	restoreError()
+	if got := GetLevel(); got != zap.InfoLevel {
+		t.Errorf("level = %v after restoring every call, want info", got)
+	}
+}
+
+func TestLevelHandler(t *testing.T) {
+	resetForTest(t)
+
+	req := httptest.NewRequest(http.MethodPut, "/log/level", strings.NewReader(`{"level":"warn"}`))
+	rec := httptest.NewRecorder()
+	LevelHandler().ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("PUT status = %d, want 200: %s", rec.Code, rec.Body)
+	}
+
+	if got := GetLevel(); got != zap.WarnLevel {
+		t.Errorf("GetLevel() = %v, want warn", got)
+	}
+}
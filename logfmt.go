@@ -0,0 +1,309 @@
+package logger
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"go.uber.org/zap/buffer"
+	"go.uber.org/zap/zapcore"
+)
+
+var logfmtPool = buffer.NewPool()
+
+// logfmtEncoder is a zapcore.Encoder writing entries as space separated
+// key=value pairs. Values containing spaces, quotes, = or control
+// characters are quoted. Arrays, objects and reflected values are
+// written as quoted JSON.
+type logfmtEncoder struct {
+	cfg *zapcore.EncoderConfig
+
+	// buf holds the fields added with With.
+	buf *buffer.Buffer
+
+	// namespace prefixes the keys added after OpenNamespace.
+	namespace string
+}
+
+// newLogfmtEncoder returns a logfmt encoder honoring the keys and
+// encoders of cfg.
+func newLogfmtEncoder(cfg zapcore.EncoderConfig) zapcore.Encoder {
+	return &logfmtEncoder{cfg: &cfg, buf: logfmtPool.Get()}
+}
+
+func (e *logfmtEncoder) Clone() zapcore.Encoder {
+	clone := &logfmtEncoder{cfg: e.cfg, buf: logfmtPool.Get(), namespace: e.namespace}
+	_, _ = clone.buf.Write(e.buf.Bytes())
+	return clone
+}
+
+func (e *logfmtEncoder) EncodeEntry(ent zapcore.Entry, fields []zapcore.Field) (*buffer.Buffer, error) {
+	final := &logfmtEncoder{cfg: e.cfg, buf: logfmtPool.Get()}
+
+	if e.cfg.TimeKey != "" && e.cfg.EncodeTime != nil {
+		final.addEncoded(e.cfg.TimeKey, func(enc zapcore.PrimitiveArrayEncoder) {
+			e.cfg.EncodeTime(ent.Time, enc)
+		})
+	}
+
+	if e.cfg.LevelKey != "" && e.cfg.EncodeLevel != nil {
+		final.addEncoded(e.cfg.LevelKey, func(enc zapcore.PrimitiveArrayEncoder) {
+			e.cfg.EncodeLevel(ent.Level, enc)
+		})
+	}
+
+	if ent.LoggerName != "" && e.cfg.NameKey != "" {
+		final.AddString(e.cfg.NameKey, ent.LoggerName)
+	}
+
+	if ent.Caller.Defined && e.cfg.CallerKey != "" && e.cfg.EncodeCaller != nil {
+		final.addEncoded(e.cfg.CallerKey, func(enc zapcore.PrimitiveArrayEncoder) {
+			e.cfg.EncodeCaller(ent.Caller, enc)
+		})
+	}
+
+	if e.cfg.MessageKey != "" {
+		final.AddString(e.cfg.MessageKey, ent.Message)
+	}
+
+	if e.buf.Len() > 0 {
+		final.separate()
+		_, _ = final.buf.Write(e.buf.Bytes())
+	}
+
+	final.namespace = e.namespace
+	for _, f := range fields {
+		f.AddTo(final)
+	}
+
+	final.namespace = ""
+	if ent.Stack != "" && e.cfg.StacktraceKey != "" {
+		final.AddString(e.cfg.StacktraceKey, ent.Stack)
+	}
+
+	lineEnding := e.cfg.LineEnding
+	if lineEnding == "" {
+		lineEnding = zapcore.DefaultLineEnding
+	}
+
+	final.buf.AppendString(lineEnding)
+
+	return final.buf, nil
+}
+
+// separate writes the space preceding a pair, unless it is the first.
+func (e *logfmtEncoder) separate() {
+	if e.buf.Len() > 0 {
+		e.buf.AppendByte(' ')
+	}
+}
+
+// addKey writes the key of a pair and the = sign.
+func (e *logfmtEncoder) addKey(key string) {
+	e.separate()
+	if e.namespace != "" {
+		e.buf.AppendString(e.namespace)
+		e.buf.AppendByte('.')
+	}
+
+	e.buf.AppendString(key)
+	e.buf.AppendByte('=')
+}
+
+// addValue writes s, quoting it if needed.
+func (e *logfmtEncoder) addValue(s string) {
+	if needsQuoting(s) {
+		e.buf.AppendString(strconv.Quote(s))
+	} else {
+		e.buf.AppendString(s)
+	}
+}
+
+func (e *logfmtEncoder) addPair(key, value string) {
+	e.addKey(key)
+	e.addValue(value)
+}
+
+// addEncoded adds key with the value appended by encode, as used by the
+// time, level, duration and caller encoders.
+func (e *logfmtEncoder) addEncoded(key string, encode func(zapcore.PrimitiveArrayEncoder)) {
+	var values stringArrayEncoder
+	encode(&values)
+	e.addPair(key, strings.Join(values, ","))
+}
+
+// addJSON adds key with the JSON encoding of the value added by add to
+// a map encoder.
+func (e *logfmtEncoder) addJSON(key string, add func(zapcore.ObjectEncoder) error) error {
+	m := zapcore.NewMapObjectEncoder()
+	if err := add(m); err != nil {
+		return err
+	}
+
+	b, err := json.Marshal(m.Fields[key])
+	if err != nil {
+		return err
+	}
+
+	e.addPair(key, string(b))
+	return nil
+}
+
+func (e *logfmtEncoder) AddArray(key string, arr zapcore.ArrayMarshaler) error {
+	return e.addJSON(key, func(enc zapcore.ObjectEncoder) error {
+		return enc.AddArray(key, arr)
+	})
+}
+
+func (e *logfmtEncoder) AddObject(key string, obj zapcore.ObjectMarshaler) error {
+	return e.addJSON(key, func(enc zapcore.ObjectEncoder) error {
+		return enc.AddObject(key, obj)
+	})
+}
+
+func (e *logfmtEncoder) AddReflected(key string, obj interface{}) error {
+	return e.addJSON(key, func(enc zapcore.ObjectEncoder) error {
+		return enc.AddReflected(key, obj)
+	})
+}
+
+func (e *logfmtEncoder) AddBinary(key string, value []byte) {
+	e.addPair(key, base64.StdEncoding.EncodeToString(value))
+}
+
+func (e *logfmtEncoder) AddByteString(key string, value []byte) {
+	e.addPair(key, string(value))
+}
+
+func (e *logfmtEncoder) AddBool(key string, value bool) {
+	e.addPair(key, strconv.FormatBool(value))
+}
+
+func (e *logfmtEncoder) AddComplex128(key string, value complex128) {
+	e.addPair(key, strconv.FormatComplex(value, 'f', -1, 128))
+}
+
+func (e *logfmtEncoder) AddComplex64(key string, value complex64) {
+	e.addPair(key, strconv.FormatComplex(complex128(value), 'f', -1, 64))
+}
+
+func (e *logfmtEncoder) AddDuration(key string, value time.Duration) {
+	if e.cfg.EncodeDuration == nil {
+		e.addPair(key, value.String())
+		return
+	}
+
+	e.addEncoded(key, func(enc zapcore.PrimitiveArrayEncoder) {
+		e.cfg.EncodeDuration(value, enc)
+	})
+}
+
+func (e *logfmtEncoder) AddFloat64(key string, value float64) {
+	e.addPair(key, formatFloat(value, 64))
+}
+
+func (e *logfmtEncoder) AddFloat32(key string, value float32) {
+	e.addPair(key, formatFloat(float64(value), 32))
+}
+
+func (e *logfmtEncoder) AddInt(key string, value int)     { e.AddInt64(key, int64(value)) }
+func (e *logfmtEncoder) AddInt32(key string, value int32) { e.AddInt64(key, int64(value)) }
+func (e *logfmtEncoder) AddInt16(key string, value int16) { e.AddInt64(key, int64(value)) }
+func (e *logfmtEncoder) AddInt8(key string, value int8)   { e.AddInt64(key, int64(value)) }
+
+func (e *logfmtEncoder) AddInt64(key string, value int64) {
+	e.addPair(key, strconv.FormatInt(value, 10))
+}
+
+func (e *logfmtEncoder) AddString(key, value string) {
+	e.addPair(key, value)
+}
+
+func (e *logfmtEncoder) AddTime(key string, value time.Time) {
+	if e.cfg.EncodeTime == nil {
+		e.addPair(key, value.Format(time.RFC3339Nano))
+		return
+	}
+
+	e.addEncoded(key, func(enc zapcore.PrimitiveArrayEncoder) {
+		e.cfg.EncodeTime(value, enc)
+	})
+}
+
+func (e *logfmtEncoder) AddUint(key string, value uint)       { e.AddUint64(key, uint64(value)) }
+func (e *logfmtEncoder) AddUint32(key string, value uint32)   { e.AddUint64(key, uint64(value)) }
+func (e *logfmtEncoder) AddUint16(key string, value uint16)   { e.AddUint64(key, uint64(value)) }
+func (e *logfmtEncoder) AddUint8(key string, value uint8)     { e.AddUint64(key, uint64(value)) }
+func (e *logfmtEncoder) AddUintptr(key string, value uintptr) { e.AddUint64(key, uint64(value)) }
+
+func (e *logfmtEncoder) AddUint64(key string, value uint64) {
+	e.addPair(key, strconv.FormatUint(value, 10))
+}
+
+func (e *logfmtEncoder) OpenNamespace(key string) {
+	if e.namespace != "" {
+		key = e.namespace + "." + key
+	}
+
+	e.namespace = key
+}
+
+// needsQuoting reports whether s must be quoted to be a logfmt value.
+func needsQuoting(s string) bool {
+	if s == "" {
+		return true
+	}
+
+	for _, r := range s {
+		if r == ' ' || r == '=' || r == '"' || r == '\\' || !unicode.IsPrint(r) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// formatFloat formats f, spelling out the special values as the JSON
+// encoder does.
+func formatFloat(f float64, bitSize int) string {
+	switch {
+	case math.IsNaN(f):
+		return "NaN"
+	case math.IsInf(f, 1):
+		return "+Inf"
+	case math.IsInf(f, -1):
+		return "-Inf"
+	}
+
+	return strconv.FormatFloat(f, 'f', -1, bitSize)
+}
+
+// stringArrayEncoder is a zapcore.PrimitiveArrayEncoder collecting the
+// appended values as strings.
+type stringArrayEncoder []string
+
+func (s *stringArrayEncoder) append(v string) { *s = append(*s, v) }
+
+func (s *stringArrayEncoder) AppendBool(v bool)             { s.append(strconv.FormatBool(v)) }
+func (s *stringArrayEncoder) AppendByteString(v []byte)     { s.append(string(v)) }
+func (s *stringArrayEncoder) AppendComplex128(v complex128) { s.append(fmt.Sprint(v)) }
+func (s *stringArrayEncoder) AppendComplex64(v complex64)   { s.append(fmt.Sprint(v)) }
+func (s *stringArrayEncoder) AppendFloat64(v float64)       { s.append(formatFloat(v, 64)) }
+func (s *stringArrayEncoder) AppendFloat32(v float32)       { s.append(formatFloat(float64(v), 32)) }
+func (s *stringArrayEncoder) AppendInt(v int)               { s.append(strconv.Itoa(v)) }
+func (s *stringArrayEncoder) AppendInt64(v int64)           { s.append(strconv.FormatInt(v, 10)) }
+func (s *stringArrayEncoder) AppendInt32(v int32)           { s.append(strconv.FormatInt(int64(v), 10)) }
+func (s *stringArrayEncoder) AppendInt16(v int16)           { s.append(strconv.FormatInt(int64(v), 10)) }
+func (s *stringArrayEncoder) AppendInt8(v int8)             { s.append(strconv.FormatInt(int64(v), 10)) }
+func (s *stringArrayEncoder) AppendString(v string)         { s.append(v) }
+func (s *stringArrayEncoder) AppendUint(v uint)             { s.append(strconv.FormatUint(uint64(v), 10)) }
+func (s *stringArrayEncoder) AppendUint64(v uint64)         { s.append(strconv.FormatUint(v, 10)) }
+func (s *stringArrayEncoder) AppendUint32(v uint32)         { s.append(strconv.FormatUint(uint64(v), 10)) }
+func (s *stringArrayEncoder) AppendUint16(v uint16)         { s.append(strconv.FormatUint(uint64(v), 10)) }
+func (s *stringArrayEncoder) AppendUint8(v uint8)           { s.append(strconv.FormatUint(uint64(v), 10)) }
+func (s *stringArrayEncoder) AppendUintptr(v uintptr)       { s.append(strconv.FormatUint(uint64(v), 10)) }
@@ -0,0 +1,21 @@
+package logger
+
+import (
+	"strings"
+	"testing"
+
+	"go.uber.org/zap"
+)
+
+func TestLogfmtQuoting(t *testing.T) {
+	l, buf := newTestLogger(t, "info", Config{Format: FormatLogfmt})
+
+	l.Info("hello world", zap.String("user", "alice"), zap.String("note", `said "hi" there`))
+
+	out := buf.String()
+	for _, want := range []string{`msg="hello world"`, ` user=alice`, ` note="said \"hi\" there"`} {
+		if !strings.Contains(out, want) {
+			t.Errorf("%q does not contain %s", out, want)
+		}
+	}
+}
@@ -1,131 +1,1019 @@
 package logger
 
 import (
+	"bytes"
 	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
 	"fmt"
-	"log"
+	"io"
 	"os"
+	"path/filepath"
 	"runtime/debug"
+	"sort"
+	"strings"
 	"sync"
+	"time"
 
+	"go.uber.org/multierr"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
-	"gopkg.in/natefinch/lumberjack.v2"
 )
 
 type ctxKey struct{}
 
+type requestIDKey struct{}
+
+type userIDKey struct{}
+
+// legacyUserIDKey is the string key user ids were historically stored
+// under. New code should use WithUserID.
+const legacyUserIDKey = "userId"
+
+// Values of APP_ENV that change where logs are written. APP_ENV is
+// matched case-insensitively and "development" and "local" mean envDev.
+const (
+	envDev  = "dev"
+	envBoth = "both"
+)
+
 var once sync.Once
 
 var logger *zap.Logger
 
+// defaultOutput is what logger writes to.
+var defaultOutput *output
+
+// initErr is the error reported while initializing logger, if any.
+var initErr error
+
 // Get initializes a zap.Logger instance if it has not been initialized
 // already and returns the same instance for subsequent calls.
-func Get(logPath, logLevel string) *zap.Logger {
+// An optional Config customizes the logger; it is only honoured on the
+// first call. When logPath is empty no log file is created and the
+// entries meant for it are written to stdout, as they are with a warning
+// when the log file cannot be opened.
+func Get(logPath, logLevel string, cfg ...Config) *zap.Logger {
+	l, _ := GetE(logPath, logLevel, cfg...)
+	return l
+}
+
+// GetE is like Get but also returns the error encountered while
+// initializing the logger, such as an invalid level. The returned
+// logger is usable even when the error is non-nil.
+func GetE(logPath, logLevel string, cfg ...Config) (*zap.Logger, error) {
 	once.Do(func() {
-		stdout := zapcore.AddSync(os.Stdout)
-
-		file := zapcore.AddSync(&lumberjack.Logger{
-			Filename:   logPath,
-			MaxSize:    3,    // log size 3MB
-			MaxBackups: 30,   // Keeps last 30 log files
-			Compress:   true, // Compress old logs
-		})
-
-		level := zap.InfoLevel
-		levelEnv := logLevel
-		if levelEnv != "" {
-			levelFromEnv, err := zapcore.ParseLevel(levelEnv)
-			if err != nil {
-				log.Println(
-					fmt.Errorf("invalid level, defaulting to INFO: %w", err),
-				)
-			}
+		logger, defaultOutput, initErr = build(logPath, logLevel, firstConfig(cfg), atomicLevel)
+	})
+
+	return logger, initErr
+}
+
+// GetWith is like Get but takes the log path and level from config and
+// applies opts after the options derived from config, e.g. zap.Fields or
+// zap.WrapCore, so that they also apply to the startup line. Like the
+// Config passed to Get, config and opts are only honoured on the first
+// call.
+func GetWith(config Config, opts ...zap.Option) *zap.Logger {
+	l, _ := GetWithE(config, opts...)
+	return l
+}
+
+// GetWithE is like GetWith but also returns the error encountered while
+// initializing the logger, as GetE does.
+func GetWithE(config Config, opts ...zap.Option) (*zap.Logger, error) {
+	once.Do(func() {
+		logger, defaultOutput, initErr = build(config.Path, config.Level, config, atomicLevel, opts...)
+	})
+
+	return logger, initErr
+}
+
+// SetDefault makes l the logger returned by Get and GetE and used by
+// FromCtx when ctx carries none, e.g. a logger built with zap directly.
+// Get no longer builds a logger afterwards, until Reset. The files of a
+// logger Get built before are left open for Close.
+func SetDefault(l *zap.Logger) {
+	once.Do(func() {})
+	logger = l
+}
+
+// output holds what a logger built by build writes to.
+type output struct {
+	// file is the rotating log file, nil when c.Writer is set or logPath
+	// is empty, and path is its path.
+	file fileSink
+	path string
+
+	// closers are closed along with the logger.
+	closers []io.Closer
+
+	// format is the format of the console and the file set by SetFormat.
+	format *formatSwitch
+
+	// debugSampled is Config.DebugSampledTraces.
+	debugSampled bool
+}
+
+// close closes the file and closers of o.
+func (o *output) close() error {
+	if o == nil {
+		return nil
+	}
+
+	// Closers may flush into the file so it is closed last
+	var err error
+	for _, c := range o.closers {
+		err = multierr.Append(err, c.Close())
+	}
+
+	if o.file != nil {
+		err = multierr.Append(err, o.file.Close())
+	}
+
+	return err
+}
+
+// fileSink is a log file that can be rotated on demand.
+type fileSink interface {
+	io.WriteCloser
+	Rotate() error
+}
+
+// closerFunc adapts a function to io.Closer.
+type closerFunc func() error
+
+func (f closerFunc) Close() error {
+	return f()
+}
 
-			level = levelFromEnv
+// build creates a logger writing to logPath as configured by c, whose
+// level is controlled by level, and returns it with its output. The
+// extra options are applied after those derived from c.
+// The returned logger is usable even when the error is non-nil.
+func build(logPath, logLevel string, c Config, level zap.AtomicLevel, extra ...zap.Option) (*zap.Logger, *output, error) {
+	parsed, err := parseLevel(logLevel)
+	level.SetLevel(parsed)
+
+	stdout := zapcore.AddSync(os.Stdout)
+
+	// A custom writer replaces the rotating file, and without a path the
+	// entries meant for the file go to stdout instead
+	out := &output{debugSampled: c.DebugSampledTraces}
+	var file zapcore.WriteSyncer
+	var openErr error
+	fileIsStdout := false
+	switch {
+	case c.StdoutOnly:
+		file = stdout
+		fileIsStdout = true
+	case c.Writer != nil:
+		file = zapcore.AddSync(c.Writer)
+	case logPath == "":
+		file = stdout
+		fileIsStdout = true
+	default:
+		if dirErr := os.MkdirAll(filepath.Dir(logPath), 0o755); dirErr != nil {
+			err = multierr.Append(err, fmt.Errorf("failed to create log directory: %w", dirErr))
+		}
+
+		var sink fileSink
+		probe := logPath
+		if c.RotateDaily {
+			daily := newDailySink(logPath, c)
+			probe = daily.currentPath()
+			sink = daily
+		} else {
+			err = multierr.Append(err, c.repair(logPath))
+			sink = newFileSink(logPath, c)
+		}
+
+		// Entries are not lost silently when the file cannot be written
+		if openErr = probeFile(probe); openErr != nil {
+			err = multierr.Append(err, fmt.Errorf("%s: %w", fallbackMessage, openErr))
+			file = stdout
+			fileIsStdout = true
+		} else {
+			out.file, out.path = sink, logPath
+			file = zapcore.AddSync(out.file)
+		}
+	}
+
+	if c.Async != nil {
+		buffered := &zapcore.BufferedWriteSyncer{
+			WS:            file,
+			Size:          c.Async.BufferSize,
+			FlushInterval: c.Async.FlushInterval,
 		}
 
-		logLevel := zap.NewAtomicLevelAt(level)
+		out.closers = append(out.closers, closerFunc(buffered.Stop))
+		file = buffered
+	}
 
-		productionCfg := zap.NewProductionEncoderConfig()
-		productionCfg.TimeKey = "timestamp"
-		productionCfg.EncodeTime = zapcore.ISO8601TimeEncoder
+	// Unless a format is configured the console gets human readable
+	// output and the file gets JSON.
+	consoleFormat, fileFormat := FormatConsole, FormatJSON
+	if c.Format != "" {
+		consoleFormat, fileFormat = c.Format, c.Format
+	}
 
-		developmentCfg := zap.NewDevelopmentEncoderConfig()
-		developmentCfg.EncodeLevel = zapcore.CapitalColorLevelEncoder
+	consoleColor := c.color(isTerminal(os.Stdout))
+	consoleEncoder := func(format string) zapcore.Encoder {
+		if c.PrettyJSON {
+			return prettyEncoder{Encoder: newEncoder(c, FormatJSON, false), lineEnding: c.lineEnding()}
+		}
 
-		consoleEncoder := zapcore.NewConsoleEncoder(productionCfg)
-		fileEncoder := zapcore.NewJSONEncoder(productionCfg)
+		if format == "" {
+			format = consoleFormat
+		}
 
-		var gitRevision string
+		return newEncoder(c, format, consoleColor)
+	}
 
-		buildInfo, ok := debug.ReadBuildInfo()
-		if ok {
-			for _, v := range buildInfo.Settings {
-				if v.Key == "vcs.revision" {
-					gitRevision = v.Value
-					break
-				}
-			}
+	fileEncoder := newEncoder(c, fileFormat, false)
+	fileEncoderFor := func(format string) zapcore.Encoder {
+		if format == "" {
+			return fileEncoder.Clone()
 		}
 
-		var core zapcore.Core
+		return newEncoder(c, format, false)
+	}
 
-		// In development env write only to console
-		// In non-dev env write only to file
-		if os.Getenv("APP_ENV") == "dev" {
-			core = zapcore.NewTee(
-				zapcore.NewCore(consoleEncoder, stdout, logLevel),
-			)
+	var consoleLevel, fileLevel zapcore.LevelEnabler = level, level
+	if c.ConsoleMinLevel != nil {
+		consoleLevel = c.ConsoleMinLevel
+	}
+
+	if c.FileMinLevel != nil {
+		fileLevel = c.FileMinLevel
+	}
+
+	// The console and the file follow SetFormat
+	out.format = newFormatSwitch()
+	consoleCore := wrapCore(c, consoleLevel, newSwitchCore(out.format, consoleEncoder, stdout, orAllLevels(c.ConsoleLevels)))
+	fileCore := wrapCore(c, fileLevel, newSwitchCore(out.format, fileEncoderFor, file, orAllLevels(c.FileLevels)))
+
+	env := appEnv()
+
+	// Routing by level needs both destinations
+	routed := c.ConsoleLevels != nil || c.FileLevels != nil
+	if routed {
+		env = envBoth
+	}
+
+	if c.StdoutOnly {
+		env = ""
+	}
+
+	var core zapcore.Core
+
+	// In development env write only to console
+	// In "both" env write to console and file
+	// In any other env write only to file
+	switch env {
+	case envDev:
+		core = consoleCore
+	case envBoth:
+		if fileIsStdout && !routed {
+			core = fileCore
+		} else {
+			core = zapcore.NewTee(fileCore, consoleCore)
+		}
+	default:
+		core = fileCore
+	}
+
+	for _, spec := range c.files() {
+		if dirErr := os.MkdirAll(filepath.Dir(spec.Path), 0o755); dirErr != nil {
+			err = multierr.Append(err, fmt.Errorf("failed to create log directory: %w", dirErr))
+		}
+
+		err = multierr.Append(err, c.repair(spec.Path))
+		sink := newFileSink(spec.Path, spec.config(c))
+		out.closers = append(out.closers, sink)
+		core = zapcore.NewTee(
+			core,
+			wrapCore(c, level, zapcore.NewCore(fileEncoder, zapcore.AddSync(sink), orAllLevels(spec.Levels))),
+		)
+	}
+
+	if c.Syslog != nil {
+		w, dialErr := dialSyslog(*c.Syslog)
+		if dialErr != nil {
+			err = multierr.Append(err, dialErr)
 		} else {
+			out.closers = append(out.closers, w)
+			core = zapcore.NewTee(core, wrapCore(c, level, newSyslogCore(fileEncoder, w, zapcore.DebugLevel)))
+		}
+	}
+
+	// Without journald its entries go to stderr rather than nowhere
+	if c.Journald != nil {
+		w, dialErr := dialJournald(*c.Journald)
+		if dialErr != nil {
+			err = multierr.Append(err, dialErr)
+			core = zapcore.NewTee(core, wrapCore(c, level, zapcore.NewCore(fileEncoder, zapcore.Lock(os.Stderr), zapcore.DebugLevel)))
+		} else {
+			out.closers = append(out.closers, w)
+			core = zapcore.NewTee(core, wrapCore(c, level, newJournaldCore(*c.Journald, w, zapcore.DebugLevel)))
+		}
+	}
+
+	if c.NetworkSink != nil {
+		w := newNetworkWriter(*c.NetworkSink)
+		out.closers = append(out.closers, w)
+		core = zapcore.NewTee(core, wrapCore(c, level, zapcore.NewCore(newEncoder(c, FormatJSON, false), w, zapcore.DebugLevel)))
+	}
+
+	// Errors are additionally copied to the error output, if any
+	if c.ErrorOutput != nil {
+		errorOutput, isFile := c.ErrorOutput.(*os.File)
+		errorEncoder := newEncoder(c, consoleFormat, c.color(isFile && isTerminal(errorOutput)))
+
+		core = zapcore.NewTee(
+			core,
+			wrapCore(c, level, zapcore.NewCore(errorEncoder, zapcore.AddSync(c.ErrorOutput), zap.ErrorLevel)),
+		)
+	}
+
+	core = zapcore.NewTee(core, wrapCore(c, level, newRecentCore(c)))
+
+	// Build info, host and static fields are attached whatever the
+	// destination
+	fields := buildFields()
+	if !c.OmitHostFields {
+		fields = append(fields, hostFields()...)
+	}
+
+	core = core.With(append(fields, stringFields(c.Fields)...))
+
+	if c.Schema != nil {
+		core = &schemaCore{Core: core, schema: c.Schema}
+	}
+
+	if c.Sampling != nil {
+		// Stopped before the sinks it writes to are closed
+		reporter := startDropReporter(core, *c.Sampling)
+		out.closers = append([]io.Closer{reporter}, out.closers...)
+
+		var sampled zapcore.Core = &reportingCore{
+			Core: zapcore.NewSamplerWithOptions(
+				core, time.Second, c.Sampling.First, c.Sampling.Thereafter,
+				zapcore.SamplerHook(reporter.hook),
+			),
+			reporter: reporter,
+		}
+
+		if c.Sampling.KeepErrors {
+			belowError := zap.LevelEnablerFunc(func(l zapcore.Level) bool {
+				return l < zap.ErrorLevel
+			})
+
 			core = zapcore.NewTee(
-				zapcore.NewCore(fileEncoder, file, logLevel).
-					With(
-						[]zapcore.Field{
-							zap.String("git_revision", gitRevision),
-							zap.String("go_version", buildInfo.GoVersion),
-						},
-					),
+				&filterCore{Core: sampled, filter: belowError},
+				&filterCore{Core: core, filter: zap.ErrorLevel},
 			)
+		} else {
+			core = sampled
 		}
+	}
 
-		logger = zap.New(core, zap.AddStacktrace(zap.ErrorLevel))
-	})
+	if c.Dedup != nil {
+		core = newDedupCore(core, *c.Dedup)
+	}
+
+	// Stopped before the sinks it syncs are closed
+	if c.FlushInterval > 0 {
+		out.closers = append([]io.Closer{startFlusher(core, c.FlushInterval)}, out.closers...)
+	}
+
+	var stacktraceLevel zapcore.LevelEnabler = zap.ErrorLevel
+	if c.StacktraceLevel != nil {
+		stacktraceLevel = c.StacktraceLevel
+	}
+
+	// Fatal entries flush every destination before ending the process
+	var onFatal zapcore.CheckWriteAction = zapcore.WriteThenFatal
+	if c.FatalPanics {
+		onFatal = zapcore.WriteThenPanic
+	}
+
+	fatalHook := &syncThen{hook: onFatal}
+	opts := []zap.Option{
+		zap.AddStacktrace(stacktraceLevel),
+		zap.WithFatalHook(fatalHook),
+	}
+	// DPanic entries panic in development to surface broken invariants
+	if appEnv() == envDev {
+		opts = append(opts, zap.Development())
+	}
+
+	if c.AddCaller {
+		opts = append(opts, zap.AddCaller())
+	}
+
+	if c.CallerSkip != 0 {
+		opts = append(opts, zap.AddCallerSkip(c.CallerSkip))
+	}
 
-	return logger
+	if c.Clock != nil {
+		opts = append(opts, zap.WithClock(c.Clock))
+	}
+
+	opts = append(opts, zap.Hooks(append([]func(zapcore.Entry) error{countLevel}, c.Hooks...)...))
+	opts = append(opts, extra...)
+
+	l := zap.New(core, opts...)
+
+	// The extra options may have wrapped the core, e.g. with zap.WrapCore
+	fatalHook.core = l.Core()
+	if openErr != nil {
+		l.Warn(fallbackMessage, zap.String("path", logPath), zap.Error(openErr))
+	}
+
+	if !c.Quiet {
+		maxSizeMB, maxBackups, compress := c.rotation()
+		l.Info("logger initialized",
+			zap.String("path", logPath),
+			zap.Stringer("log_level", level.Level()),
+			zap.String("console_format", consoleFormat),
+			zap.String("file_format", fileFormat),
+			zap.String("app_env", env),
+			zap.Int("max_size_mb", maxSizeMB),
+			zap.Int("max_backups", maxBackups),
+			zap.Int("max_age_days", c.MaxAgeDays),
+			zap.Bool("compress", compress),
+		)
+	}
+
+	return l, out, err
+}
+
+const fallbackMessage = "cannot write the log file, logging to stdout instead"
+
+// probeFile checks that the log file at path can be opened for writing,
+// creating it if needed.
+func probeFile(path string) error {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return err
+	}
+
+	return f.Close()
+}
+
+// syncThen is a zapcore.CheckWriteHook syncing core before running hook.
+type syncThen struct {
+	core zapcore.Core
+	hook zapcore.CheckWriteHook
+}
+
+func (h syncThen) OnWrite(ce *zapcore.CheckedEntry, fields []zapcore.Field) {
+	_ = h.core.Sync()
+	h.hook.OnWrite(ce, fields)
+}
+
+// Sync flushes any buffered log entries. Callers should
+//
+//	defer logger.Sync()
+//
+// in main so that entries are not lost on shutdown. The error syncing
+// stdout or stderr reports on some platforms is ignored.
+func Sync() error {
+	if logger == nil {
+		return nil
+	}
+
+	var errs error
+	for _, err := range multierr.Errors(logger.Sync()) {
+		if !isBenignSyncError(err) {
+			errs = multierr.Append(errs, err)
+		}
+	}
+
+	return errs
+}
+
+// Close flushes any buffered log entries and closes the log file and
+// any connection. The file is reopened if the logger is used afterwards.
+func Close() error {
+	return multierr.Append(Sync(), defaultOutput.close())
+}
+
+// Rotate closes the log file of the logger returned by Get and opens a
+// new one, keeping the current file as a backup, e.g. before a backup
+// job. It does nothing when the logger writes to no log file.
+func Rotate() error {
+	if defaultOutput == nil || defaultOutput.file == nil {
+		return nil
+	}
+
+	return defaultOutput.file.Rotate()
+}
+
+// FlushAndSnapshot syncs the logger returned by Get and returns the
+// content of its log file, up to the last complete entry, so that tests
+// can assert on it without sleeping. It is meant for tests: the whole
+// file is read into memory.
+func FlushAndSnapshot() ([]byte, error) {
+	if err := Sync(); err != nil {
+		return nil, err
+	}
+
+	if defaultOutput == nil || defaultOutput.file == nil {
+		return nil, errors.New("the logger writes to no log file")
+	}
+
+	path := defaultOutput.path
+	if daily, ok := defaultOutput.file.(*dailySink); ok {
+		path = daily.currentPath()
+	}
+
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	// An entry being written concurrently is left out
+	return b[:bytes.LastIndexByte(b, '\n')+1], nil
+}
+
+// Reset discards the loggers built by Get, GetNamed and Audit so that
+// the next call initializes a new one. It is intended for tests that
+// need different configurations in the same process and must not be
+// called concurrently with Get. It is safe to call before Get.
+func Reset() {
+	_ = defaultOutput.close()
+
+	once = sync.Once{}
+	logger = nil
+	defaultOutput = nil
+	initErr = nil
+	atomicLevel.SetLevel(zap.InfoLevel)
+	resetNamed()
+	resetAudit()
+	recent.Store(nil)
+	resetLevelCounts()
+	resetLevelOverrides()
+}
+
+// unknownBuildInfo is reported for build info that is unavailable.
+const unknownBuildInfo = "unknown"
+
+// readBuildInfo is debug.ReadBuildInfo, replaceable in tests.
+var readBuildInfo = debug.ReadBuildInfo
+
+// buildFields returns the git_revision and go_version fields of the
+// running binary. Both are "unknown" when the build info is unavailable,
+// as happens when module info is stripped from the binary.
+func buildFields() []zapcore.Field {
+	gitRevision, goVersion := unknownBuildInfo, unknownBuildInfo
+
+	if buildInfo, ok := readBuildInfo(); ok && buildInfo != nil {
+		goVersion = buildInfo.GoVersion
+		for _, v := range buildInfo.Settings {
+			if v.Key == "vcs.revision" {
+				gitRevision = v.Value
+				break
+			}
+		}
+	}
+
+	return []zapcore.Field{
+		zap.String("git_revision", gitRevision),
+		zap.String("go_version", goVersion),
+	}
+}
+
+// hostname and pid identify the process, computed once at start.
+var (
+	hostname = lookupHostname()
+	pid      = os.Getpid()
+)
+
+// lookupHostname returns the host name, or "unknown" if it cannot be
+// determined.
+func lookupHostname() string {
+	name, err := os.Hostname()
+	if err != nil {
+		return unknownBuildInfo
+	}
+
+	return name
+}
+
+// hostFields returns the hostname and pid fields.
+func hostFields() []zapcore.Field {
+	return []zapcore.Field{
+		zap.String("hostname", hostname),
+		zap.Int("pid", pid),
+	}
+}
+
+// filterCore is a zapcore.Core only passing the entries enabled by
+// filter on to the wrapped core.
+type filterCore struct {
+	zapcore.Core
+	filter zapcore.LevelEnabler
+}
+
+func (c *filterCore) Enabled(l zapcore.Level) bool {
+	return c.filter.Enabled(l) && c.Core.Enabled(l)
+}
+
+func (c *filterCore) With(fields []zapcore.Field) zapcore.Core {
+	return &filterCore{Core: c.Core.With(fields), filter: c.filter}
+}
+
+func (c *filterCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if !c.filter.Enabled(ent.Level) {
+		return ce
+	}
+
+	return c.Core.Check(ent, ce)
+}
+
+// wrapCore applies the wrappers configured by c to core, which must
+// write to a single destination, and only passes it the entries enabled
+// by level, the logger level of the destination. Core filters entries
+// by level itself only to route them, e.g. for FileSpec.Levels.
+func wrapCore(c Config, level zapcore.LevelEnabler, core zapcore.Core) zapcore.Core {
+	if c.Redact != nil {
+		core = &redactCore{Core: core, r: c.Redact}
+	}
+
+	return newLevelCore(core, level)
+}
+
+// orAllLevels returns filter, or a LevelEnabler enabling every level if
+// filter is nil.
+func orAllLevels(filter zapcore.LevelEnabler) zapcore.LevelEnabler {
+	if filter == nil {
+		return zapcore.DebugLevel
+	}
+
+	return filter
+}
+
+// levelCore is a zapcore.Core only passing the entries enabled by the
+// logger level on to the wrapped core. Its children created by With for
+// a forceLevel field also pass on the entries at the forced level and
+// above, which the wrapped core still filters by their own level.
+type levelCore struct {
+	zapcore.Core
+	level  zapcore.LevelEnabler
+	forced zapcore.Level
+}
+
+func newLevelCore(core zapcore.Core, level zapcore.LevelEnabler) *levelCore {
+	return &levelCore{Core: core, level: level, forced: zapcore.InvalidLevel}
+}
+
+func (c *levelCore) Enabled(l zapcore.Level) bool {
+	return (l >= c.forced || c.level.Enabled(l)) && c.Core.Enabled(l)
+}
+
+func (c *levelCore) With(fields []zapcore.Field) zapcore.Core {
+	clone := *c
+
+	var rest []zapcore.Field
+	for _, f := range fields {
+		if l, ok := f.Interface.(forcedLevel); ok && f.Type == zapcore.SkipType {
+			if zapcore.Level(l) < clone.forced {
+				clone.forced = zapcore.Level(l)
+			}
+
+			continue
+		}
+
+		rest = append(rest, f)
+	}
+
+	if len(rest) > 0 {
+		clone.Core = c.Core.With(rest)
+	}
+
+	return &clone
+}
+
+func (c *levelCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if !c.Enabled(ent.Level) {
+		return ce
+	}
+
+	return c.Core.Check(ent, ce)
+}
+
+// forcedLevel is the level forced by a forceLevel field.
+type forcedLevel zapcore.Level
+
+// forceLevel returns a field writing nothing that makes the levelCores
+// of the Logger it is added to pass on the entries at level and above
+// whatever the logger level.
+func forceLevel(level zapcore.Level) zapcore.Field {
+	return zapcore.Field{Type: zapcore.SkipType, Interface: forcedLevel(level)}
+}
+
+// stringFields converts m into string fields sorted by key.
+func stringFields(m map[string]string) []zapcore.Field {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+
+	sort.Strings(keys)
+
+	fields := make([]zapcore.Field, 0, len(keys))
+	for _, k := range keys {
+		fields = append(fields, zap.String(k, m[k]))
+	}
+
+	return fields
+}
+
+// newEncoder returns an encoder for format configured by c. Levels are
+// colored only for the console format when color is true.
+func newEncoder(c Config, format string, color bool) zapcore.Encoder {
+	encoderCfg := zap.NewProductionEncoderConfig()
+	encoderCfg.TimeKey = defaultTimeKey
+	encoderCfg.EncodeTime = zapcore.ISO8601TimeEncoder
+
+	if c.TimeKey != "" {
+		encoderCfg.TimeKey = c.TimeKey
+	}
+
+	if c.TimeEncoder != nil {
+		encoderCfg.EncodeTime = c.TimeEncoder
+	}
+
+	if c.UTC {
+		encodeTime := encoderCfg.EncodeTime
+		encoderCfg.EncodeTime = func(t time.Time, enc zapcore.PrimitiveArrayEncoder) {
+			encodeTime(t.UTC(), enc)
+		}
+	}
+
+	if c.DurationEncoder != nil {
+		encoderCfg.EncodeDuration = c.DurationEncoder
+	}
+
+	if c.CallerEncoder != nil {
+		encoderCfg.EncodeCaller = c.CallerEncoder
+	}
+
+	c.Keys.apply(&encoderCfg)
+	encoderCfg.LineEnding = c.lineEnding()
+
+	switch format {
+	case FormatJSON:
+		return zapcore.NewJSONEncoder(encoderCfg)
+	case FormatLogfmt:
+		return newLogfmtEncoder(encoderCfg)
+	}
+
+	if color {
+		encoderCfg.EncodeLevel = zapcore.CapitalColorLevelEncoder
+	}
+
+	return zapcore.NewConsoleEncoder(encoderCfg)
+}
+
+// isTerminal reports whether f is attached to a terminal.
+func isTerminal(f *os.File) bool {
+	fi, err := f.Stat()
+	if err != nil {
+		return false
+	}
+
+	return fi.Mode()&os.ModeCharDevice != 0
+}
+
+// appEnv returns APP_ENV trimmed and lower-cased, with its aliases of
+// envDev mapped to envDev.
+func appEnv() string {
+	env := strings.ToLower(strings.TrimSpace(os.Getenv("APP_ENV")))
+
+	switch env {
+	case envDev, "development", "local":
+		return envDev
+	}
+
+	return env
+}
+
+// parseLevel parses logLevel, defaulting to INFO when it is empty or
+// invalid.
+func parseLevel(logLevel string) (zapcore.Level, error) {
+	if logLevel == "" {
+		return zap.InfoLevel, nil
+	}
+
+	level, err := zapcore.ParseLevel(logLevel)
+	if err != nil {
+		return zap.InfoLevel, fmt.Errorf("invalid level, defaulting to INFO: %w", err)
+	}
+
+	return level, nil
 }
 
 // FromCtx returns the Logger associated with the ctx. If no logger
 // is associated, the default logger is returned, unless it is nil
 // in which case a disabled logger is returned.
 func FromCtx(ctx context.Context) *zap.Logger {
-	if l, ok := ctx.Value(ctxKey{}).(*zap.Logger); ok {
-		return l
+	if l, ok := ctx.Value(ctxKey{}).(*zap.Logger); ok && l != nil {
+		return escalateSampled(ctx, l)
 	} else if l := logger; l != nil {
-		return l
+		return escalateSampled(ctx, l)
 	}
 
+	return Disabled()
+}
+
+// FromCtxSugared returns FromCtx(ctx).Sugar(), for code preferring the
+// printf-style and loosely typed key-value methods of the sugared API
+// over the speed of typed fields.
+func FromCtxSugared(ctx context.Context) *zap.SugaredLogger {
+	return FromCtx(ctx).Sugar()
+}
+
+// FromCtxTagged is like FromCtx but, once ctx is cancelled or past its
+// deadline, the returned Logger also carries ctx_cancelled=true and the
+// ctx_err field, to spot work done after cancellation.
+func FromCtxTagged(ctx context.Context) *zap.Logger {
+	l := FromCtx(ctx)
+	if err := ctx.Err(); err != nil {
+		return l.With(zap.Bool("ctx_cancelled", true), zap.NamedError("ctx_err", err))
+	}
+
+	return l
+}
+
+// FromCtxWithDeadline is like FromCtx but, when ctx has a deadline, the
+// returned Logger also carries the milliseconds left until it as
+// deadline_remaining_ms, negative once it has passed. The time left is
+// computed by FromCtxWithDeadline, so call it where the entry is logged.
+func FromCtxWithDeadline(ctx context.Context) *zap.Logger {
+	l := FromCtx(ctx)
+	if deadline, ok := ctx.Deadline(); ok {
+		return l.With(zap.Int64("deadline_remaining_ms", time.Until(deadline).Milliseconds()))
+	}
+
+	return l
+}
+
+// Disabled returns a Logger that discards every entry, for libraries
+// that want to log nothing without initializing Get.
+func Disabled() *zap.Logger {
 	return zap.NewNop()
 }
 
-// WithCtx returns a copy of ctx with the Logger attached.
+// WithCtx returns a copy of ctx with the Logger attached. A nil Logger
+// is not stored and ctx is returned unchanged.
 func WithCtx(ctx context.Context, l *zap.Logger) context.Context {
+	if l == nil {
+		return ctx
+	}
+
 	if lp, ok := ctx.Value(ctxKey{}).(*zap.Logger); ok {
 		if lp == l {
 			// Do not store same logger.
 			return ctx
 		}
+	} else if l == logger {
+		// FromCtx falls back to it anyway
+		return ctx
 	}
 
 	return context.WithValue(ctx, ctxKey{}, l)
 }
 
+// ContextHasLogger reports whether a Logger is attached to ctx, rather
+// than FromCtx falling back to the one returned by Get.
+func ContextHasLogger(ctx context.Context) bool {
+	l, ok := ctx.Value(ctxKey{}).(*zap.Logger)
+	return ok && l != nil
+}
+
+// WithFields returns a copy of ctx with a child of its Logger that adds
+// fields to every entry. Fields of repeated calls accumulate.
+func WithFields(ctx context.Context, fields ...zap.Field) context.Context {
+	return WithCtx(ctx, FromCtx(ctx).With(fields...))
+}
+
+// WithCtxFields is WithFields: unlike WithCtx it keeps the fields of the
+// Logger already in ctx.
+func WithCtxFields(ctx context.Context, fields ...zap.Field) context.Context {
+	return WithFields(ctx, fields...)
+}
+
+// WithComponent returns a copy of ctx with a child of its Logger named
+// after the component. Nested components produce dotted names such as
+// server.db.query in the logger field.
+func WithComponent(ctx context.Context, name string) context.Context {
+	return WithCtx(ctx, FromCtx(ctx).Named(name))
+}
+
+// ForWorker returns a copy of ctx with a child of its Logger adding the
+// worker id as worker_id to every entry, to be called at the top of each
+// goroutine fanned out, e.g. by an errgroup.
+func ForWorker(ctx context.Context, id int) context.Context {
+	return WithFields(ctx, zap.Int("worker_id", id))
+}
+
+// WithCallerSkip returns a copy of ctx with a child of its Logger that
+// skips n more stack frames when reporting the caller, for helpers
+// wrapping the Logger that should report the location of their caller.
+func WithCallerSkip(ctx context.Context, n int) context.Context {
+	return WithCtx(ctx, FromCtx(ctx).WithOptions(zap.AddCallerSkip(n)))
+}
+
+// WithForceLevel returns a copy of ctx with a child of its Logger that
+// logs the entries at level and above even when the logger level is
+// higher, e.g. to debug a single request in production. Forced entries
+// still only reach the destinations taking their level: a debug entry
+// is not copied to ErrorOutput or to a file restricted to errors. Only
+// the loggers built by this package can be forced.
+func WithForceLevel(ctx context.Context, level zapcore.Level) context.Context {
+	return WithFields(ctx, forceLevel(level))
+}
+
+// WithSampler returns a copy of ctx with a child of its Logger sampling
+// its entries, e.g. for a noisy endpoint: within each second the first
+// first entries with the same level and message are logged, then only
+// every thereafter-th one.
+func WithSampler(ctx context.Context, first, thereafter int) context.Context {
+	return WithCtx(ctx, FromCtx(ctx).WithOptions(zap.WrapCore(func(core zapcore.Core) zapcore.Core {
+		return zapcore.NewSamplerWithOptions(core, time.Second, first, thereafter)
+	})))
+}
+
 func GetContextLogger(ctx context.Context) (context.Context, *zap.Logger) {
 	log := FromCtx(ctx)
 	context := WithCtx(ctx, log)
 	return context, log
 }
 
+// WithUserID returns a copy of ctx carrying the user id.
+func WithUserID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, userIDKey{}, id)
+}
+
+// LogUserId returns a user_id field holding the user id stored in ctx by
+// WithUserID. For backward compatibility it falls back to the value
+// stored under the legacy "userId" string key.
 func LogUserId(ctx context.Context) zapcore.Field {
-	return zap.Any("user_id", ctx.Value("userId"))
+	if id, ok := ctx.Value(userIDKey{}).(string); ok {
+		return zap.String("user_id", id)
+	}
+
+	return zap.Any("user_id", ctx.Value(legacyUserIDKey))
+}
+
+// LogUserIDString is like LogUserId but avoids reflection when the user
+// id stored under the legacy key is a string, as it usually is. Other
+// values still fall back to zap.Any.
+func LogUserIDString(ctx context.Context) zapcore.Field {
+	if id, ok := ctx.Value(userIDKey{}).(string); ok {
+		return zap.String("user_id", id)
+	}
+
+	v := ctx.Value(legacyUserIDKey)
+	if id, ok := v.(string); ok {
+		return zap.String("user_id", id)
+	}
+
+	return zap.Any("user_id", v)
+}
+
+// WithRequestID returns a copy of ctx carrying the request id and a
+// child of its Logger adding it to every entry as request_id.
+func WithRequestID(ctx context.Context, id string) context.Context {
+	ctx = context.WithValue(ctx, requestIDKey{}, id)
+	return WithFields(ctx, LogRequestID(ctx))
+}
+
+// NewRequestID returns a new random request id.
+func NewRequestID() string {
+	var b [16]byte
+	_, _ = rand.Read(b[:])
+	return hex.EncodeToString(b[:])
+}
+
+// RequestIDFromCtx returns the request id stored in ctx by WithRequestID,
+// or an empty string if there is none.
+func RequestIDFromCtx(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// LogRequestID returns a request_id field holding the request id stored
+// in ctx by WithRequestID. The field is skipped if there is none.
+func LogRequestID(ctx context.Context) zapcore.Field {
+	id, ok := ctx.Value(requestIDKey{}).(string)
+	if !ok {
+		return zap.Skip()
+	}
+
+	return zap.String("request_id", id)
 }
@@ -0,0 +1,1442 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"runtime/debug"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestMain(m *testing.M) {
+	// Tests opting into an environment set it with t.Setenv
+	os.Unsetenv("APP_ENV")
+	os.Exit(m.Run())
+}
+
+// syncBuffer is a bytes.Buffer safe for concurrent use, standing for a
+// log destination.
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.buf.String()
+}
+
+// resetForTest resets the package now and once the test is over.
+func resetForTest(tb testing.TB) {
+	tb.Helper()
+
+	Reset()
+	tb.Cleanup(Reset)
+}
+
+// newTestLogger resets the package and returns the logger built by Get
+// at level with c, writing quietly to the returned buffer instead of a
+// file.
+func newTestLogger(t *testing.T, level string, c Config) (*zap.Logger, *syncBuffer) {
+	t.Helper()
+
+	resetForTest(t)
+
+	buf := &syncBuffer{}
+	c.Writer = buf
+	c.Quiet = true
+
+	return Get("", level, c), buf
+}
+
+// captureStdout redirects os.Stdout to a file until the test is over
+// and returns a function reading what was written to it so far. It must
+// be called before the logger is built.
+func captureStdout(t *testing.T) func() string {
+	t.Helper()
+
+	f, err := os.CreateTemp(t.TempDir(), "stdout")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	stdout := os.Stdout
+	os.Stdout = f
+	t.Cleanup(func() {
+		os.Stdout = stdout
+		f.Close()
+	})
+
+	return func() string {
+		b, err := os.ReadFile(f.Name())
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		return string(b)
+	}
+}
+
+// decodeEntries decodes the JSON entries written one per line in s.
+func decodeEntries(t *testing.T, s string) []map[string]interface{} {
+	t.Helper()
+
+	var entries []map[string]interface{}
+	for _, line := range strings.Split(strings.TrimSpace(s), "\n") {
+		if line == "" {
+			continue
+		}
+
+		var entry map[string]interface{}
+		if err := json.Unmarshal([]byte(line), &entry); err != nil {
+			t.Fatalf("invalid JSON entry %q: %v", line, err)
+		}
+
+		entries = append(entries, entry)
+	}
+
+	return entries
+}
+
+func TestGetEInvalidLevel(t *testing.T) {
+	resetForTest(t)
+
+	l, err := GetE("", "bogus", Config{Writer: &syncBuffer{}, Quiet: true})
+	if err == nil {
+		t.Error("GetE returned no error for an invalid level")
+	}
+
+	if l == nil {
+		t.Fatal("GetE returned a nil logger")
+	}
+
+	if l.Core().Enabled(zap.DebugLevel) || !l.Core().Enabled(zap.InfoLevel) {
+		t.Error("logger is not at info level")
+	}
+}
+
+func TestStartupLine(t *testing.T) {
+	for _, quiet := range []bool{false, true} {
+		resetForTest(t)
+
+		buf := &syncBuffer{}
+		path := filepath.Join(t.TempDir(), "app.log")
+		Get(path, "debug", Config{Writer: buf, Quiet: quiet})
+
+		entries := decodeEntries(t, buf.String())
+		if quiet {
+			if len(entries) != 0 {
+				t.Errorf("Quiet: got %v, want no startup line", entries)
+			}
+			continue
+		}
+
+		if len(entries) != 1 || entries[0]["msg"] != "logger initialized" {
+			t.Fatalf("got %v, want the startup line", entries)
+		}
+
+		if e := entries[0]; e["path"] != path || e["log_level"] != "debug" {
+			t.Errorf("got %v, want the path and the level", e)
+		}
+	}
+}
+
+func TestGetEUnwritablePath(t *testing.T) {
+	resetForTest(t)
+	stdout := captureStdout(t)
+
+	// A directory cannot be created under a regular file
+	notDir := filepath.Join(t.TempDir(), "file")
+	if err := os.WriteFile(notDir, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	l, err := GetE(filepath.Join(notDir, "app.log"), "info", Config{Quiet: true})
+	if err == nil || !strings.Contains(err.Error(), fallbackMessage) {
+		t.Errorf("GetE error = %v, want the fallback reported", err)
+	}
+
+	l.Info("still logged")
+
+	entries := decodeEntries(t, stdout())
+	if len(entries) != 2 || entries[0]["msg"] != fallbackMessage || entries[1]["msg"] != "still logged" {
+		t.Errorf("stdout got %v, want the fallback warning and the entry", entries)
+	}
+}
+
+func TestBothEnvWritesConsoleAndFile(t *testing.T) {
+	t.Setenv("APP_ENV", "both")
+	stdout := captureStdout(t)
+	l, file := newTestLogger(t, "info", Config{})
+
+	l.Info("everywhere")
+	_ = Sync()
+
+	if !strings.Contains(file.String(), "everywhere") {
+		t.Error("entry missing from the file")
+	}
+
+	if !strings.Contains(stdout(), "everywhere") {
+		t.Error("entry missing from the console")
+	}
+}
+
+func TestBothEnvFormats(t *testing.T) {
+	t.Setenv("APP_ENV", "both")
+	stdout := captureStdout(t)
+	l, file := newTestLogger(t, "info", Config{})
+
+	l.Info("formatted")
+	SetLevel(zap.WarnLevel)
+	l.Info("dropped")
+	_ = Sync()
+
+	entries := decodeEntries(t, file.String())
+	if len(entries) != 1 || entries[0]["msg"] != "formatted" {
+		t.Errorf("got %v in the file, want one JSON entry", entries)
+	}
+
+	console := stdout()
+	if !strings.Contains(console, "formatted") || strings.HasPrefix(console, "{") {
+		t.Errorf("console got %q, want a console formatted entry", console)
+	}
+
+	if strings.Contains(console, "dropped") {
+		t.Error("console ignores the level")
+	}
+}
+
+func TestFormat(t *testing.T) {
+	t.Run("json to stdout", func(t *testing.T) {
+		resetForTest(t)
+		stdout := captureStdout(t)
+
+		Get("", "info", Config{Format: FormatJSON, Quiet: true}).Info("hello")
+
+		entries := decodeEntries(t, stdout())
+		if len(entries) != 1 || entries[0]["msg"] != "hello" {
+			t.Errorf("got %v, want one JSON entry", entries)
+		}
+	})
+
+	t.Run("console to file", func(t *testing.T) {
+		resetForTest(t)
+		path := filepath.Join(t.TempDir(), "app.log")
+
+		Get(path, "info", Config{Format: FormatConsole, Quiet: true}).Info("hello")
+
+		b, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if line := string(b); !strings.Contains(line, "\tinfo\thello\t") || strings.HasPrefix(line, "{") {
+			t.Errorf("got %q, want a console entry", line)
+		}
+	})
+}
+
+func TestErrorOutput(t *testing.T) {
+	errorOutput := &syncBuffer{}
+	l, buf := newTestLogger(t, "info", Config{ErrorOutput: errorOutput})
+
+	l.Info("routine")
+	l.Error("failure")
+
+	if got := buf.String(); !strings.Contains(got, "routine") || !strings.Contains(got, "failure") {
+		t.Errorf("primary output = %q, want both entries", got)
+	}
+
+	if got := errorOutput.String(); strings.Contains(got, "routine") || !strings.Contains(got, "failure") {
+		t.Errorf("error output = %q, want only the error", got)
+	}
+}
+
+func TestSync(t *testing.T) {
+	// The console is the stdout of the test, typically a pipe
+	t.Setenv("APP_ENV", "both")
+	resetForTest(t)
+
+	Get(filepath.Join(t.TempDir(), "app.log"), "info", Config{Quiet: true}).Info("flushed")
+
+	if err := Sync(); err != nil {
+		t.Errorf("Sync() = %v, want nil", err)
+	}
+}
+
+func TestSyncBeforeGet(t *testing.T) {
+	resetForTest(t)
+
+	if err := Sync(); err != nil {
+		t.Errorf("Sync() = %v, want nil", err)
+	}
+}
+
+func TestReset(t *testing.T) {
+	resetForTest(t)
+
+	first := &syncBuffer{}
+	Get("", "info", Config{Writer: first, Quiet: true})
+
+	Reset()
+
+	second := &syncBuffer{}
+	Get("", "debug", Config{Writer: second, Quiet: true}).Debug("reinitialized")
+
+	if first.String() != "" {
+		t.Errorf("first logger got %q after Reset", first)
+	}
+
+	if !strings.Contains(second.String(), "reinitialized") {
+		t.Error("Get did not build a new logger after Reset")
+	}
+}
+
+func TestRotate(t *testing.T) {
+	resetForTest(t)
+
+	if err := Rotate(); err != nil {
+		t.Fatalf("Rotate before Get: %v", err)
+	}
+
+	// Kept uncompressed, as compression happens in the background
+	compress := false
+	dir := t.TempDir()
+	l := Get(filepath.Join(dir, "app.log"), "info", Config{Compress: &compress, Quiet: true})
+	l.Info("before")
+
+	if err := Rotate(); err != nil {
+		t.Fatal(err)
+	}
+	l.Info("after")
+
+	files := dirFiles(t, dir)
+	if len(files) != 2 || files[1] != "app.log" || !strings.HasPrefix(files[0], "app-") {
+		t.Fatalf("files = %v, want app.log and a backup", files)
+	}
+
+	b, err := os.ReadFile(filepath.Join(dir, files[0]))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.Contains(string(b), "before") || strings.Contains(string(b), "after") {
+		t.Errorf("backup = %q, want only the entry before Rotate", b)
+	}
+}
+
+func TestFlushAndSnapshot(t *testing.T) {
+	resetForTest(t)
+
+	if _, err := FlushAndSnapshot(); err == nil {
+		t.Error("FlushAndSnapshot returned no error without a log file")
+	}
+
+	resetForTest(t)
+	l := Get(filepath.Join(t.TempDir(), "app.log"), "info", Config{
+		Async: &AsyncConfig{BufferSize: 1 << 20, FlushInterval: time.Hour},
+		Quiet: true,
+	})
+	l.Info("snapshotted")
+
+	b, err := FlushAndSnapshot()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	entries := decodeEntries(t, string(b))
+	if len(entries) != 1 || entries[0]["msg"] != "snapshotted" {
+		t.Errorf("got %v, want the buffered entry", entries)
+	}
+}
+
+func TestRequestID(t *testing.T) {
+	ctx := WithRequestID(context.Background(), "req-1")
+
+	if got := RequestIDFromCtx(ctx); got != "req-1" {
+		t.Errorf("RequestIDFromCtx() = %q, want req-1", got)
+	}
+
+	f := LogRequestID(ctx)
+	if f.Key != "request_id" || f.String != "req-1" {
+		t.Errorf("LogRequestID() = %s=%q, want request_id=req-1", f.Key, f.String)
+	}
+
+	if got := LogRequestID(context.Background()); got != zap.Skip() {
+		t.Errorf("LogRequestID() without an id = %v, want zap.Skip()", got)
+	}
+}
+
+func TestLogUserId(t *testing.T) {
+	tests := []struct {
+		name string
+		ctx  context.Context
+		want interface{}
+	}{
+		{"typed key", WithUserID(context.Background(), "u1"), "u1"},
+		{"legacy key", context.WithValue(context.Background(), legacyUserIDKey, "u2"), "u2"},
+		{"legacy non-string", context.WithValue(context.Background(), legacyUserIDKey, 42), int64(42)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			enc := zapcore.NewMapObjectEncoder()
+			LogUserId(tt.ctx).AddTo(enc)
+
+			if got := enc.Fields["user_id"]; got != tt.want {
+				t.Errorf("user_id = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHostFields(t *testing.T) {
+	name, err := os.Hostname()
+	if err != nil {
+		t.Skip(err)
+	}
+
+	l, buf := newTestLogger(t, "info", Config{})
+	l.Info("located")
+
+	entry := decodeEntries(t, buf.String())[0]
+	if entry["hostname"] != name || entry["pid"] != float64(os.Getpid()) {
+		t.Errorf("hostname = %v and pid = %v, want %s and %d", entry["hostname"], entry["pid"], name, os.Getpid())
+	}
+
+	l, buf = newTestLogger(t, "info", Config{OmitHostFields: true})
+	l.Info("anonymous")
+
+	entry = decodeEntries(t, buf.String())[0]
+	if _, ok := entry["hostname"]; ok {
+		t.Error("hostname written with OmitHostFields")
+	}
+
+	if _, ok := entry["pid"]; ok {
+		t.Error("pid written with OmitHostFields")
+	}
+}
+
+func TestStaticFields(t *testing.T) {
+	for _, env := range []string{"", "dev"} {
+		t.Run("APP_ENV="+env, func(t *testing.T) {
+			t.Setenv("APP_ENV", env)
+			stdout := captureStdout(t)
+			l, buf := newTestLogger(t, "info", Config{Format: FormatJSON, Fields: map[string]string{"service": "api"}})
+
+			l.Info("hello")
+
+			out := buf.String()
+			if env == "dev" {
+				out = stdout()
+			}
+
+			entries := decodeEntries(t, out)
+			if len(entries) != 1 || entries[0]["service"] != "api" {
+				t.Errorf("got %v, want one entry with service=api", entries)
+			}
+		})
+	}
+}
+
+// withoutBuildInfo makes the build info unavailable until the test is
+// over.
+func withoutBuildInfo(t *testing.T) {
+	readBuildInfo = func() (*debug.BuildInfo, bool) { return nil, false }
+	t.Cleanup(func() { readBuildInfo = debug.ReadBuildInfo })
+}
+
+func TestBuildFieldsInDev(t *testing.T) {
+	t.Setenv("APP_ENV", "dev")
+	withoutBuildInfo(t)
+	stdout := captureStdout(t)
+	l, _ := newTestLogger(t, "info", Config{Format: FormatJSON})
+
+	l.Info("hello")
+
+	entries := decodeEntries(t, stdout())
+	if len(entries) != 1 {
+		t.Fatalf("got %d entries, want 1", len(entries))
+	}
+
+	for _, key := range []string{"git_revision", "go_version"} {
+		if got := entries[0][key]; got != "unknown" {
+			t.Errorf("%s = %v, want unknown", key, got)
+		}
+	}
+}
+
+func TestGetWithoutBuildInfo(t *testing.T) {
+	withoutBuildInfo(t)
+	l, buf := newTestLogger(t, "info", Config{})
+
+	l.Info("usable")
+
+	entries := decodeEntries(t, buf.String())
+	if len(entries) != 1 || entries[0]["go_version"] != "unknown" {
+		t.Errorf("got %v, want one entry with go_version=unknown", entries)
+	}
+}
+
+func TestSampling(t *testing.T) {
+	l, buf := newTestLogger(t, "info", Config{Sampling: &SamplingConfig{First: 10, Thereafter: 100}})
+
+	for i := 0; i < 1000; i++ {
+		l.Info("repeated")
+	}
+
+	if n := strings.Count(buf.String(), "repeated"); n == 0 || n > 100 {
+		t.Errorf("%d of 1000 entries written, want a sample", n)
+	}
+}
+
+func TestTimeEncoder(t *testing.T) {
+	l, buf := newTestLogger(t, "info", Config{TimeKey: "ts", TimeEncoder: zapcore.EpochMillisTimeEncoder})
+
+	l.Info("hello")
+
+	entries := decodeEntries(t, buf.String())
+	if len(entries) != 1 {
+		t.Fatalf("got %d entries, want 1", len(entries))
+	}
+
+	if _, ok := entries[0]["ts"].(float64); !ok {
+		t.Errorf("ts = %#v, want a number", entries[0]["ts"])
+	}
+
+	if _, ok := entries[0]["timestamp"]; ok {
+		t.Error("timestamp key still written")
+	}
+}
+
+func TestUTC(t *testing.T) {
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.FixedZone("CET", 3600))
+
+	tests := []struct {
+		utc  bool
+		want string
+	}{
+		{false, "2024-03-01T12:00:00.000+0100"},
+		{true, "2024-03-01T11:00:00.000Z"},
+	}
+
+	for _, tt := range tests {
+		l, buf := newTestLogger(t, "info", Config{UTC: tt.utc, Clock: fixedClock{now}})
+		l.Info("hello")
+
+		if got := decodeEntries(t, buf.String())[0]["timestamp"]; got != tt.want {
+			t.Errorf("UTC=%v: timestamp = %v, want %s", tt.utc, got, tt.want)
+		}
+	}
+}
+
+func TestWithFieldsAccumulates(t *testing.T) {
+	_, buf := newTestLogger(t, "info", Config{})
+
+	ctx := WithFields(context.Background(), zap.String("a", "1"))
+	ctx = WithFields(ctx, zap.String("b", "2"))
+	FromCtx(ctx).Info("hello")
+
+	entries := decodeEntries(t, buf.String())
+	if len(entries) != 1 || entries[0]["a"] != "1" || entries[0]["b"] != "2" {
+		t.Errorf("got %v, want one entry with a=1 and b=2", entries)
+	}
+}
+
+func TestWriter(t *testing.T) {
+	resetForTest(t)
+
+	var buf bytes.Buffer
+	Get("", "info", Config{Writer: &buf, Quiet: true}).Info("to buffer", zap.Int("n", 1))
+
+	entries := decodeEntries(t, buf.String())
+	if len(entries) != 1 {
+		t.Fatalf("got %d entries, want 1", len(entries))
+	}
+
+	if entries[0]["msg"] != "to buffer" || entries[0]["level"] != "info" || entries[0]["n"] != float64(1) {
+		t.Errorf("got %v", entries[0])
+	}
+}
+
+func TestStacktraceLevel(t *testing.T) {
+	tests := []struct {
+		name  string
+		level zapcore.LevelEnabler
+		log   zapcore.Level
+		want  bool
+	}{
+		{"default error", nil, zap.ErrorLevel, true},
+		{"default warn", nil, zap.WarnLevel, false},
+		{"warn", zap.WarnLevel, zap.WarnLevel, true},
+		{"disabled", NoStacktrace, zap.ErrorLevel, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l, buf := newTestLogger(t, "info", Config{StacktraceLevel: tt.level})
+
+			l.Log(tt.log, "failure")
+
+			entries := decodeEntries(t, buf.String())
+			if _, got := entries[0]["stacktrace"]; got != tt.want {
+				t.Errorf("stacktrace present = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAddCaller(t *testing.T) {
+	l, buf := newTestLogger(t, "info", Config{AddCaller: true})
+
+	l.Info("located")
+
+	entries := decodeEntries(t, buf.String())
+	if caller, _ := entries[0]["caller"].(string); !strings.Contains(caller, "logger_test.go:") {
+		t.Errorf("caller = %q, want logger_test.go", caller)
+	}
+}
+
+func TestEmptyPathCreatesNoFile(t *testing.T) {
+	resetForTest(t)
+	stdout := captureStdout(t)
+
+	dir := t.TempDir()
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = os.Chdir(wd) })
+
+	Get("", "info", Config{Quiet: true}).Info("to stdout")
+	_ = Close()
+
+	if files, _ := os.ReadDir(dir); len(files) != 0 {
+		t.Errorf("files created: %v", files)
+	}
+
+	if !strings.Contains(stdout(), "to stdout") {
+		t.Error("entry not written to stdout")
+	}
+}
+
+func TestDisabled(t *testing.T) {
+	if Disabled().Core().Enabled(zap.FatalLevel) {
+		t.Error("Disabled logger enables entries")
+	}
+}
+
+func TestCreatesLogDirectory(t *testing.T) {
+	resetForTest(t)
+
+	path := filepath.Join(t.TempDir(), "nested", "dir", "app.log")
+	_, err := GetE(path, "info", Config{Quiet: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	Get(path, "info").Info("persisted")
+	_ = Sync()
+
+	b, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.Contains(string(b), "persisted") {
+		t.Errorf("log file = %q", b)
+	}
+}
+
+func TestLevelRouting(t *testing.T) {
+	stdout := captureStdout(t)
+	l, file := newTestLogger(t, "debug", Config{
+		ConsoleLevels: zap.LevelEnablerFunc(func(l zapcore.Level) bool {
+			return l < zapcore.WarnLevel
+		}),
+		FileLevels: zapcore.WarnLevel,
+	})
+
+	l.Debug("detail")
+	l.Error("failure")
+
+	if got := stdout(); !strings.Contains(got, "detail") || strings.Contains(got, "failure") {
+		t.Errorf("console = %q, want only the debug entry", got)
+	}
+
+	if got := file.String(); strings.Contains(got, "detail") || !strings.Contains(got, "failure") {
+		t.Errorf("file = %q, want only the error", got)
+	}
+}
+
+func TestMinLevels(t *testing.T) {
+	t.Setenv("APP_ENV", "both")
+	stdout := captureStdout(t)
+	l, file := newTestLogger(t, "info", Config{
+		ConsoleMinLevel: zap.NewAtomicLevelAt(zap.WarnLevel),
+		FileMinLevel:    zap.NewAtomicLevelAt(zap.DebugLevel),
+	})
+
+	l.Debug("detail")
+	l.Error("failure")
+
+	if got := stdout(); strings.Contains(got, "detail") || !strings.Contains(got, "failure") {
+		t.Errorf("console = %q, want only the error", got)
+	}
+
+	if got := file.String(); !strings.Contains(got, "detail") || !strings.Contains(got, "failure") {
+		t.Errorf("file = %q, want both entries", got)
+	}
+}
+
+func TestAsyncSync(t *testing.T) {
+	resetForTest(t)
+
+	path := filepath.Join(t.TempDir(), "app.log")
+	l := Get(path, "info", Config{
+		Async: &AsyncConfig{BufferSize: 1 << 20, FlushInterval: time.Hour},
+		Quiet: true,
+	})
+
+	l.Info("buffered")
+
+	if b, _ := os.ReadFile(path); strings.Contains(string(b), "buffered") {
+		t.Fatal("entry written before Sync")
+	}
+
+	if err := Sync(); err != nil {
+		t.Fatal(err)
+	}
+
+	if b, _ := os.ReadFile(path); !strings.Contains(string(b), "buffered") {
+		t.Error("entry not written by Sync")
+	}
+}
+
+func BenchmarkFile(b *testing.B) {
+	benchmarks := []struct {
+		name  string
+		async *AsyncConfig
+	}{
+		{"sync", nil},
+		{"async", &AsyncConfig{}},
+	}
+
+	for _, bm := range benchmarks {
+		b.Run(bm.name, func(b *testing.B) {
+			resetForTest(b)
+			l := Get(filepath.Join(b.TempDir(), "app.log"), "info", Config{Async: bm.async, Quiet: true})
+
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				l.Info("benchmark", zap.Int("i", i))
+			}
+
+			b.StopTimer()
+			_ = Sync()
+		})
+	}
+}
+
+func TestDurationEncoder(t *testing.T) {
+	l, buf := newTestLogger(t, "info", Config{DurationEncoder: zapcore.StringDurationEncoder})
+
+	l.Info("timed", zap.Duration("elapsed", 1500*time.Millisecond))
+
+	if got := decodeEntries(t, buf.String())[0]["elapsed"]; got != "1.5s" {
+		t.Errorf("elapsed = %#v, want \"1.5s\"", got)
+	}
+}
+
+func TestFromCtxSugared(t *testing.T) {
+	resetForTest(t)
+
+	// Without a logger the disabled one is used
+	FromCtxSugared(context.Background()).Infow("nowhere")
+
+	l, buf := newTestLogger(t, "info", Config{})
+	ctx := WithCtx(context.Background(), l)
+
+	FromCtxSugared(ctx).Debugw("hidden", "n", 1)
+	FromCtxSugared(ctx).Infow("shown", "n", 2)
+	SetLevel(zap.DebugLevel)
+	FromCtxSugared(ctx).Debugf("shown %d", 3)
+
+	entries := decodeEntries(t, buf.String())
+	if len(entries) != 2 {
+		t.Fatalf("got %d entries, want 2", len(entries))
+	}
+
+	if entries[0]["msg"] != "shown" || entries[0]["n"] != float64(2) {
+		t.Errorf("got %v, want the info entry", entries[0])
+	}
+
+	if entries[1]["msg"] != "shown 3" || entries[1]["level"] != "debug" {
+		t.Errorf("got %v, want the debug entry after SetLevel", entries[1])
+	}
+}
+
+func TestFromCtxTagged(t *testing.T) {
+	_, buf := newTestLogger(t, "info", Config{})
+
+	live := context.Background()
+	cancelled, cancel := context.WithCancel(live)
+	cancel()
+
+	FromCtxTagged(live).Info("live")
+	FromCtxTagged(cancelled).Info("cancelled")
+
+	entries := decodeEntries(t, buf.String())
+	if _, ok := entries[0]["ctx_cancelled"]; ok {
+		t.Error("ctx_cancelled logged for a live context")
+	}
+
+	if entries[1]["ctx_cancelled"] != true || entries[1]["ctx_err"] != context.Canceled.Error() {
+		t.Errorf("got %v, want ctx_cancelled and ctx_err", entries[1])
+	}
+}
+
+func TestFromCtxWithDeadline(t *testing.T) {
+	l, buf := newTestLogger(t, "info", Config{})
+	ctx := WithCtx(context.Background(), l)
+
+	withDeadline, cancel := context.WithTimeout(ctx, time.Hour)
+	defer cancel()
+	passed, cancelPassed := context.WithDeadline(ctx, time.Now().Add(-time.Second))
+	defer cancelPassed()
+
+	FromCtxWithDeadline(withDeadline).Info("budget")
+	FromCtxWithDeadline(passed).Info("late")
+	FromCtxWithDeadline(ctx).Info("unbounded")
+
+	entries := decodeEntries(t, buf.String())
+	if len(entries) != 3 {
+		t.Fatalf("got %d entries, want 3", len(entries))
+	}
+
+	if got, ok := entries[0]["deadline_remaining_ms"].(float64); !ok || got <= 0 || got > float64(time.Hour.Milliseconds()) {
+		t.Errorf("deadline_remaining_ms = %v, want up to an hour", entries[0]["deadline_remaining_ms"])
+	}
+
+	if got, ok := entries[1]["deadline_remaining_ms"].(float64); !ok || got > -1000 {
+		t.Errorf("deadline_remaining_ms = %v past the deadline, want below -1000", entries[1]["deadline_remaining_ms"])
+	}
+
+	if _, ok := entries[2]["deadline_remaining_ms"]; ok {
+		t.Error("deadline_remaining_ms logged without a deadline")
+	}
+}
+
+func TestWithComponent(t *testing.T) {
+	_, buf := newTestLogger(t, "info", Config{})
+
+	ctx := WithComponent(WithComponent(context.Background(), "a"), "b")
+	FromCtx(ctx).Info("nested")
+
+	if got := decodeEntries(t, buf.String())[0]["logger"]; got != "a.b" {
+		t.Errorf("logger = %v, want a.b", got)
+	}
+}
+
+func TestFatalPanics(t *testing.T) {
+	w := &syncCounter{}
+	resetForTest(t)
+	l := Get("", "info", Config{Writer: w, Quiet: true, FatalPanics: true})
+
+	defer func() {
+		if recover() == nil {
+			t.Fatal("Fatal did not panic")
+		}
+
+		if !strings.Contains(w.String(), "terminating") {
+			t.Error("entry not written before panicking")
+		}
+
+		if w.syncs.Load() == 0 {
+			t.Error("destinations not synced before panicking")
+		}
+	}()
+
+	l.Fatal("terminating")
+}
+
+func TestSamplingKeepErrors(t *testing.T) {
+	l, buf := newTestLogger(t, "info", Config{
+		Sampling: &SamplingConfig{First: 5, Thereafter: 100, KeepErrors: true},
+	})
+
+	for i := 0; i < 500; i++ {
+		l.Info("noisy")
+		l.Error("failure")
+	}
+
+	out := buf.String()
+	if n := strings.Count(out, `"noisy"`); n == 0 || n > 50 {
+		t.Errorf("%d of 500 info entries written, want a sample", n)
+	}
+
+	if n := strings.Count(out, `"failure"`); n != 500 {
+		t.Errorf("%d of 500 errors written, want all", n)
+	}
+}
+
+func TestSamplingKeepErrorsWithForceLevel(t *testing.T) {
+	_, buf := newTestLogger(t, "error", Config{
+		Sampling: &SamplingConfig{First: 5, Thereafter: 100, KeepErrors: true},
+	})
+
+	forced := FromCtx(WithForceLevel(context.Background(), zap.DebugLevel))
+	for i := 0; i < 500; i++ {
+		forced.Debug("noisy")
+	}
+
+	if n := strings.Count(buf.String(), `"noisy"`); n == 0 || n > 50 {
+		t.Errorf("%d of 500 forced debug entries written, want a sample", n)
+	}
+}
+
+func TestWithSampler(t *testing.T) {
+	l, buf := newTestLogger(t, "info", Config{})
+	ctx := WithCtx(context.Background(), l)
+	sampled := WithSampler(ctx, 5, 100)
+
+	for i := 0; i < 200; i++ {
+		FromCtx(sampled).Info("noisy")
+		FromCtx(ctx).Info("normal")
+	}
+
+	out := buf.String()
+	if n := strings.Count(out, `"noisy"`); n < 5 || n > 10 {
+		t.Errorf("%d of 200 sampled entries written, want a sample", n)
+	}
+
+	if n := strings.Count(out, `"normal"`); n != 200 {
+		t.Errorf("%d of 200 entries written, want all", n)
+	}
+}
+
+// logThroughWrapper logs msg through a one level helper, as applications
+// using CallerSkip do.
+func logThroughWrapper(l *zap.Logger, msg string) {
+	l.Info(msg)
+}
+
+func TestCallerSkip(t *testing.T) {
+	l, buf := newTestLogger(t, "info", Config{AddCaller: true, CallerSkip: 1})
+
+	_, _, line, _ := runtime.Caller(0)
+	logThroughWrapper(l, "wrapped")
+
+	want := fmt.Sprintf("logger_test.go:%d", line+1)
+	if caller, _ := decodeEntries(t, buf.String())[0]["caller"].(string); !strings.HasSuffix(caller, want) {
+		t.Errorf("caller = %q, want %s", caller, want)
+	}
+}
+
+func TestForWorker(t *testing.T) {
+	l, buf := newTestLogger(t, "info", Config{})
+	ctx := WithCtx(context.Background(), l)
+
+	var wg sync.WaitGroup
+	for id := 0; id < 5; id++ {
+		wg.Add(1)
+		go func(id int) {
+			defer wg.Done()
+
+			ctx := ForWorker(ctx, id)
+			FromCtx(ctx).Info(fmt.Sprintf("worker %d", id))
+		}(id)
+	}
+	wg.Wait()
+
+	entries := decodeEntries(t, buf.String())
+	if len(entries) != 5 {
+		t.Fatalf("got %d entries, want 5", len(entries))
+	}
+
+	for _, e := range entries {
+		if want := fmt.Sprintf("worker %v", e["worker_id"]); e["msg"] != want {
+			t.Errorf("got %v, want worker_id matching the message", e)
+		}
+	}
+}
+
+func TestWithCallerSkip(t *testing.T) {
+	l, buf := newTestLogger(t, "info", Config{AddCaller: true})
+
+	ctx := WithCallerSkip(WithCtx(context.Background(), l), 1)
+	_, _, line, _ := runtime.Caller(0)
+	logThroughWrapper(FromCtx(ctx), "wrapped")
+
+	want := fmt.Sprintf("logger_test.go:%d", line+1)
+	if caller, _ := decodeEntries(t, buf.String())[0]["caller"].(string); !strings.HasSuffix(caller, want) {
+		t.Errorf("caller = %q, want %s", caller, want)
+	}
+}
+
+// fixedClock is a zapcore.Clock frozen at a time.
+type fixedClock struct {
+	t time.Time
+}
+
+func (c fixedClock) Now() time.Time { return c.t }
+
+func (c fixedClock) NewTicker(d time.Duration) *time.Ticker { return time.NewTicker(d) }
+
+func TestClock(t *testing.T) {
+	frozen := time.Date(2024, 6, 1, 12, 30, 0, 0, time.UTC)
+	l, buf := newTestLogger(t, "info", Config{Clock: fixedClock{frozen}, TimeEncoder: zapcore.RFC3339TimeEncoder})
+
+	l.Info("frozen")
+
+	if got := decodeEntries(t, buf.String())[0]["timestamp"]; got != "2024-06-01T12:30:00Z" {
+		t.Errorf("timestamp = %v, want 2024-06-01T12:30:00Z", got)
+	}
+}
+
+func TestHooks(t *testing.T) {
+	var errs int
+	countErrors := func(ent zapcore.Entry) error {
+		if ent.Level == zap.ErrorLevel {
+			errs++
+		}
+
+		return nil
+	}
+
+	l, _ := newTestLogger(t, "info", Config{Hooks: []func(zapcore.Entry) error{countErrors}})
+
+	l.Info("fine")
+	l.Error("first")
+	l.Error("second")
+	l.Debug("below the level")
+
+	if errs != 2 {
+		t.Errorf("hook counted %d errors, want 2", errs)
+	}
+}
+
+func TestAppEnv(t *testing.T) {
+	tests := map[string]string{
+		"Development": envDev,
+		"DEV":         envDev,
+		" local ":     envDev,
+		"Both":        envBoth,
+		"production":  "production",
+		"":            "",
+	}
+
+	for value, want := range tests {
+		t.Setenv("APP_ENV", value)
+
+		if got := appEnv(); got != want {
+			t.Errorf("APP_ENV=%q: appEnv() = %q, want %q", value, got, want)
+		}
+	}
+}
+
+func TestProductionWritesToFileOnly(t *testing.T) {
+	t.Setenv("APP_ENV", "production")
+	stdout := captureStdout(t)
+	l, file := newTestLogger(t, "info", Config{})
+
+	l.Info("persisted")
+
+	if !strings.Contains(file.String(), "persisted") || stdout() != "" {
+		t.Error("production entries not written to the file only")
+	}
+}
+
+func TestDPanic(t *testing.T) {
+	dpanics := func(l *zap.Logger) (panicked bool) {
+		defer func() { panicked = recover() != nil }()
+		l.DPanic("broken invariant")
+		return false
+	}
+
+	t.Run("dev", func(t *testing.T) {
+		t.Setenv("APP_ENV", "dev")
+		captureStdout(t)
+		l, _ := newTestLogger(t, "info", Config{})
+
+		if !dpanics(l) {
+			t.Error("DPanic did not panic")
+		}
+	})
+
+	t.Run("production", func(t *testing.T) {
+		t.Setenv("APP_ENV", "production")
+		l, file := newTestLogger(t, "info", Config{})
+
+		if dpanics(l) {
+			t.Error("DPanic panicked")
+		}
+
+		if !strings.Contains(file.String(), "broken invariant") {
+			t.Error("DPanic entry not logged")
+		}
+	})
+}
+
+func TestWithForceLevel(t *testing.T) {
+	_, buf := newTestLogger(t, "info", Config{})
+
+	forced := WithForceLevel(context.Background(), zap.DebugLevel)
+	FromCtx(forced).Debug("forced")
+	FromCtx(context.Background()).Debug("dropped")
+
+	entries := decodeEntries(t, buf.String())
+	if len(entries) != 1 || entries[0]["msg"] != "forced" || entries[0]["level"] != "debug" {
+		t.Errorf("got %v, want only the forced debug entry", entries)
+	}
+
+	if got := LevelCounts()[zap.DebugLevel]; got != 1 {
+		t.Errorf("hooks counted %d debug entries, want 1", got)
+	}
+}
+
+func TestWithForceLevelKeepsLevelFilters(t *testing.T) {
+	errorsLog := filepath.Join(t.TempDir(), "errors.log")
+	errorOutput := &syncBuffer{}
+	_, buf := newTestLogger(t, "error", Config{
+		Files:       []FileSpec{{Path: errorsLog, Levels: zapcore.ErrorLevel}},
+		ErrorOutput: errorOutput,
+	})
+
+	recentErrors := RecentErrors(10)
+	t.Cleanup(func() { recent.Store(nil) })
+
+	FromCtx(WithForceLevel(context.Background(), zap.DebugLevel)).Debug("forced")
+	_ = Sync()
+
+	entries := decodeEntries(t, buf.String())
+	if len(entries) != 1 || entries[0]["msg"] != "forced" {
+		t.Errorf("got %v, want the forced entry in the main output", entries)
+	}
+
+	if b, _ := os.ReadFile(errorsLog); len(b) != 0 {
+		t.Errorf("errors.log = %q, want it empty", b)
+	}
+
+	if got := errorOutput.String(); got != "" {
+		t.Errorf("ErrorOutput got %q", got)
+	}
+
+	rec := httptest.NewRecorder()
+	recentErrors.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/debug/errors", nil))
+	if got := rec.Body.String(); got != "[]" {
+		t.Errorf("RecentErrors = %s, want none", got)
+	}
+}
+
+func TestFilesByLevel(t *testing.T) {
+	dir := t.TempDir()
+	errorLog, combinedLog := filepath.Join(dir, "error.log"), filepath.Join(dir, "combined.log")
+	l, _ := newTestLogger(t, "info", Config{Files: []FileSpec{
+		{Path: errorLog, Levels: zapcore.ErrorLevel},
+		{Path: combinedLog},
+	}})
+
+	l.Info("routine")
+	l.Error("failure")
+	_ = Sync()
+
+	read := func(path string) string {
+		b, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		return string(b)
+	}
+
+	if got := read(errorLog); strings.Contains(got, "routine") || !strings.Contains(got, "failure") {
+		t.Errorf("error.log = %q, want only the error", got)
+	}
+
+	if got := read(combinedLog); !strings.Contains(got, "routine") || !strings.Contains(got, "failure") {
+		t.Errorf("combined.log = %q, want both entries", got)
+	}
+}
+
+func TestSetDefault(t *testing.T) {
+	resetForTest(t)
+
+	custom := zap.NewExample()
+	SetDefault(custom)
+
+	if got := FromCtx(context.Background()); got != custom {
+		t.Error("FromCtx does not fall back to the default logger")
+	}
+
+	if got := Get("", "debug", Config{Writer: &syncBuffer{}, Quiet: true}); got != custom {
+		t.Error("Get built a logger over the default one")
+	}
+}
+
+func TestGetWithOptions(t *testing.T) {
+	resetForTest(t)
+
+	buf := &syncBuffer{}
+	l := GetWith(Config{Level: "debug", Writer: buf, Quiet: true}, zap.Fields(zap.String("x", "y")))
+
+	l.Debug("with option")
+
+	entry := decodeEntries(t, buf.String())[0]
+	if entry["x"] != "y" || entry["level"] != "debug" {
+		t.Errorf("got %v, want x=y at debug", entry)
+	}
+}
+
+func TestGetWithOptionsOnStartupLine(t *testing.T) {
+	resetForTest(t)
+
+	buf := &syncBuffer{}
+	GetWith(Config{Writer: buf}, zap.Fields(zap.String("x", "y")))
+
+	entry := decodeEntries(t, buf.String())[0]
+	if entry["msg"] != "logger initialized" || entry["x"] != "y" {
+		t.Errorf("got %v, want the startup line with x=y", entry)
+	}
+}
+
+func TestGetWithOptionsSyncedOnFatal(t *testing.T) {
+	resetForTest(t)
+
+	extra := &syncCounter{}
+	l := GetWith(Config{Writer: &syncBuffer{}, Quiet: true, FatalPanics: true}, zap.WrapCore(func(core zapcore.Core) zapcore.Core {
+		return zapcore.NewTee(core, zapcore.NewCore(zapcore.NewJSONEncoder(zap.NewProductionEncoderConfig()), extra, zap.InfoLevel))
+	}))
+
+	defer func() {
+		if recover() == nil {
+			t.Fatal("Fatal did not panic")
+		}
+
+		if extra.syncs.Load() == 0 {
+			t.Error("the core added by zap.WrapCore was not synced on Fatal")
+		}
+	}()
+
+	l.Fatal("terminating")
+}
+
+func TestGetWithE(t *testing.T) {
+	resetForTest(t)
+
+	l, err := GetWithE(Config{Level: "bogus", Writer: &syncBuffer{}, Quiet: true})
+	if err == nil {
+		t.Error("GetWithE returned no error for an invalid level")
+	}
+
+	if l == nil {
+		t.Error("GetWithE returned no logger")
+	}
+}
+
+func TestWithCtxNilLogger(t *testing.T) {
+	resetForTest(t)
+
+	ctx := WithCtx(context.Background(), nil)
+	if ContextHasLogger(ctx) {
+		t.Error("nil logger stored in the context")
+	}
+
+	l := FromCtx(ctx)
+	if l == nil {
+		t.Fatal("FromCtx returned nil")
+	}
+
+	l.Info("discarded")
+}
+
+func TestWithCtxSkipsStorage(t *testing.T) {
+	l, _ := newTestLogger(t, "info", Config{})
+	other := l.Named("other")
+
+	bare := context.Background()
+	if ctx := WithCtx(bare, l); ctx != bare || ContextHasLogger(ctx) {
+		t.Error("default logger stored in a context without a logger")
+	}
+
+	withOther := WithCtx(bare, other)
+	if !ContextHasLogger(withOther) || FromCtx(withOther) != other {
+		t.Fatal("other logger not stored")
+	}
+
+	if ctx := WithCtx(withOther, other); ctx != withOther {
+		t.Error("same logger stored again")
+	}
+
+	// The default logger replaces another one
+	if ctx := WithCtx(withOther, l); FromCtx(ctx) != l {
+		t.Error("default logger not stored over another logger")
+	}
+}
+
+func BenchmarkWithCtx(b *testing.B) {
+	resetForTest(b)
+	l := Get("", "info", Config{Writer: &syncBuffer{}, Quiet: true})
+	other := l.Named("other")
+	withOther := WithCtx(context.Background(), other)
+
+	benchmarks := []struct {
+		name string
+		ctx  context.Context
+		l    *zap.Logger
+	}{
+		{"same logger", withOther, other},
+		{"default logger", context.Background(), l},
+		{"new logger", withOther, l.Named("new")},
+	}
+
+	for _, bm := range benchmarks {
+		b.Run(bm.name, func(b *testing.B) {
+			b.ReportAllocs()
+
+			for i := 0; i < b.N; i++ {
+				_ = WithCtx(bm.ctx, bm.l)
+			}
+		})
+	}
+}
+
+func TestFromCtxIgnoresStoredNil(t *testing.T) {
+	_, buf := newTestLogger(t, "info", Config{})
+
+	ctx := context.WithValue(context.Background(), ctxKey{}, (*zap.Logger)(nil))
+	FromCtx(ctx).Info("default")
+
+	if !strings.Contains(buf.String(), "default") {
+		t.Error("FromCtx did not fall back to the default logger")
+	}
+}
+
+func TestLogUserIDString(t *testing.T) {
+	tests := []struct {
+		name     string
+		ctx      context.Context
+		want     interface{}
+		wantType zapcore.FieldType
+	}{
+		{"typed key", WithUserID(context.Background(), "u1"), "u1", zapcore.StringType},
+		{"legacy string", context.WithValue(context.Background(), legacyUserIDKey, "u2"), "u2", zapcore.StringType},
+		{"legacy non-string", context.WithValue(context.Background(), legacyUserIDKey, 42), int64(42), zapcore.Int64Type},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := LogUserIDString(tt.ctx)
+			if f.Type != tt.wantType {
+				t.Errorf("field type = %v, want %v", f.Type, tt.wantType)
+			}
+
+			enc := zapcore.NewMapObjectEncoder()
+			f.AddTo(enc)
+			if got := enc.Fields["user_id"]; got != tt.want {
+				t.Errorf("user_id = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func BenchmarkUserIDField(b *testing.B) {
+	ctx := context.WithValue(context.Background(), legacyUserIDKey, "u1")
+	enc := zapcore.NewJSONEncoder(zap.NewProductionEncoderConfig())
+
+	benchmarks := []struct {
+		name  string
+		field func(context.Context) zapcore.Field
+	}{
+		{"LogUserId", LogUserId},
+		{"LogUserIDString", LogUserIDString},
+	}
+
+	for _, bm := range benchmarks {
+		b.Run(bm.name, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				buf, _ := enc.EncodeEntry(zapcore.Entry{}, []zapcore.Field{bm.field(ctx)})
+				buf.Free()
+			}
+		})
+	}
+}
+
+func TestColor(t *testing.T) {
+	for _, color := range []bool{false, true} {
+		t.Run(fmt.Sprint("color=", color), func(t *testing.T) {
+			t.Setenv("APP_ENV", "dev")
+			stdout := captureStdout(t)
+			l, _ := newTestLogger(t, "info", Config{Color: &color})
+
+			l.Info("plain")
+
+			if got := strings.Contains(stdout(), "\x1b["); got != color {
+				t.Errorf("ANSI sequences present = %v, want %v", got, color)
+			}
+		})
+	}
+}
+
+func TestWithCtxFields(t *testing.T) {
+	_, buf := newTestLogger(t, "info", Config{})
+
+	ctx := WithCtxFields(context.Background(), zap.String("tenant", "acme"))
+	ctx = WithCtxFields(ctx, zap.String("order", "o-1"))
+	FromCtx(ctx).Info("layered")
+
+	entry := decodeEntries(t, buf.String())[0]
+	if entry["tenant"] != "acme" || entry["order"] != "o-1" {
+		t.Errorf("got %v, want both layers of fields", entry)
+	}
+}
+
+func TestStdoutOnly(t *testing.T) {
+	t.Setenv("APP_ENV", "both")
+	resetForTest(t)
+	stdout := captureStdout(t)
+
+	dir := t.TempDir()
+	Get(filepath.Join(dir, "app.log"), "info", Config{
+		StdoutOnly: true,
+		Files:      []FileSpec{{Path: filepath.Join(dir, "error.log")}},
+		Quiet:      true,
+	}).Info("serverless")
+	_ = Close()
+
+	if files := dirFiles(t, dir); len(files) != 0 {
+		t.Errorf("files created: %v", files)
+	}
+
+	entries := decodeEntries(t, stdout())
+	if len(entries) != 1 || entries[0]["msg"] != "serverless" {
+		t.Errorf("stdout = %v, want one JSON entry", entries)
+	}
+}
@@ -0,0 +1,41 @@
+package logger
+
+import (
+	"sync/atomic"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// levelCounts counts the entries written per level, indexed from
+// zapcore.DebugLevel.
+var levelCounts [zapcore.FatalLevel - zapcore.DebugLevel + 1]atomic.Uint64
+
+// countLevel is the hook counting every entry written.
+func countLevel(ent zapcore.Entry) error {
+	if ent.Level >= zapcore.DebugLevel && ent.Level <= zapcore.FatalLevel {
+		levelCounts[ent.Level-zapcore.DebugLevel].Add(1)
+	}
+
+	return nil
+}
+
+// LevelCounts returns the number of entries written per level by the
+// loggers of the package since they were built. Levels without entries
+// are omitted.
+func LevelCounts() map[zapcore.Level]uint64 {
+	counts := make(map[zapcore.Level]uint64)
+	for i := range levelCounts {
+		if n := levelCounts[i].Load(); n > 0 {
+			counts[zapcore.DebugLevel+zapcore.Level(i)] = n
+		}
+	}
+
+	return counts
+}
+
+// resetLevelCounts zeroes the counts returned by LevelCounts.
+func resetLevelCounts() {
+	for i := range levelCounts {
+		levelCounts[i].Store(0)
+	}
+}
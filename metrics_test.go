@@ -0,0 +1,40 @@
+package logger
+
+import (
+	"sync"
+	"testing"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestLevelCounts(t *testing.T) {
+	l, _ := newTestLogger(t, "info", Config{})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			l.Debug("filtered")
+			l.Info("info")
+			l.Info("info")
+			l.Warn("warn")
+		}()
+	}
+	wg.Wait()
+	l.Error("error")
+
+	want := map[zapcore.Level]uint64{zap.InfoLevel: 20, zap.WarnLevel: 10, zap.ErrorLevel: 1}
+	got := LevelCounts()
+	if len(got) != len(want) {
+		t.Errorf("LevelCounts() = %v, want %v", got, want)
+	}
+
+	for level, n := range want {
+		if got[level] != n {
+			t.Errorf("%s count = %d, want %d", level, got[level], n)
+		}
+	}
+}
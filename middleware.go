@@ -0,0 +1,98 @@
+package logger
+
+import (
+	"net/http"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// RequestIDHeader is the header Middleware reads the request id from and
+// echoes it back in.
+const RequestIDHeader = "X-Request-ID"
+
+// Middleware seeds the context of every request with a logger carrying
+// its method, path and request id, and logs a line with the status code
+// and duration once the request has been served. The request id is
+// read from the X-Request-ID header, or generated if absent.
+func Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+
+		id := r.Header.Get(RequestIDHeader)
+		if id == "" {
+			id = NewRequestID()
+		}
+
+		w.Header().Set(RequestIDHeader, id)
+
+		ctx := WithRequestID(r.Context(), id)
+		log := FromCtx(ctx).With(
+			zap.String("method", r.Method),
+			zap.String("path", r.URL.Path),
+		)
+
+		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(rec.responseWriter(), r.WithContext(WithCtx(ctx, log)))
+
+		log.Info("request completed",
+			zap.Int("status", rec.status),
+			zap.Duration("duration", time.Since(start)),
+		)
+	})
+}
+
+// statusRecorder records the status code written to a ResponseWriter.
+type statusRecorder struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	if !r.wroteHeader {
+		r.status = status
+		r.wroteHeader = true
+	}
+
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *statusRecorder) Write(b []byte) (int, error) {
+	r.wroteHeader = true
+	return r.ResponseWriter.Write(b)
+}
+
+// Unwrap returns the underlying ResponseWriter for http.ResponseController.
+func (r *statusRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
+
+// responseWriter returns r as a ResponseWriter that also implements
+// http.Flusher and http.Hijacker when the wrapped ResponseWriter does,
+// e.g. for server-sent events and websockets.
+func (r *statusRecorder) responseWriter() http.ResponseWriter {
+	flusher, canFlush := r.ResponseWriter.(http.Flusher)
+	hijacker, canHijack := r.ResponseWriter.(http.Hijacker)
+
+	switch {
+	case canFlush && canHijack:
+		return struct {
+			*statusRecorder
+			http.Flusher
+			http.Hijacker
+		}{r, flusher, hijacker}
+	case canFlush:
+		return struct {
+			*statusRecorder
+			http.Flusher
+		}{r, flusher}
+	case canHijack:
+		return struct {
+			*statusRecorder
+			http.Hijacker
+		}{r, hijacker}
+	}
+
+	return r
+}
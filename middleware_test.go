@@ -0,0 +1,119 @@
+package logger
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestMiddleware(t *testing.T) {
+	_, buf := newTestLogger(t, "info", Config{})
+
+	handler := Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		FromCtx(r.Context()).Info("handling")
+		w.WriteHeader(http.StatusTeapot)
+	}))
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/tea", nil))
+
+	entries := decodeEntries(t, buf.String())
+	if len(entries) != 2 {
+		t.Fatalf("got %d entries, want 2", len(entries))
+	}
+
+	completed := entries[1]
+	if completed["msg"] != "request completed" || completed["status"] != float64(http.StatusTeapot) {
+		t.Errorf("completion entry = %v", completed)
+	}
+
+	id, _ := completed["request_id"].(string)
+	if id == "" {
+		t.Error("completion entry has no request_id")
+	}
+
+	if entries[0]["request_id"] != id || entries[0]["path"] != "/tea" {
+		t.Errorf("handler entry = %v, want the request fields", entries[0])
+	}
+
+	if got := rec.Header().Get(RequestIDHeader); got != id {
+		t.Errorf("%s header = %q, want %q", RequestIDHeader, got, id)
+	}
+}
+
+func TestMiddlewareFacadeRequestIDOnce(t *testing.T) {
+	_, buf := newTestLogger(t, "info", Config{})
+
+	handler := Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		Info(r.Context(), "handling")
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/tea", nil)
+	req.Header.Set(RequestIDHeader, "req-1")
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	line, _, _ := strings.Cut(buf.String(), "\n")
+	if n := strings.Count(line, `"request_id"`); n != 1 {
+		t.Errorf("facade entry %s has %d request_id keys, want 1", line, n)
+	}
+
+	if entry := decodeEntries(t, line)[0]; entry["request_id"] != "req-1" {
+		t.Errorf("request_id = %v, want req-1", entry["request_id"])
+	}
+}
+
+func TestMiddlewareKeepsInterfaces(t *testing.T) {
+	newTestLogger(t, "info", Config{})
+
+	var canFlush, canHijack bool
+	handler := Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var f http.Flusher
+		f, canFlush = w.(http.Flusher)
+		_, canHijack = w.(http.Hijacker)
+
+		if canFlush {
+			f.Flush()
+		}
+	}))
+
+	// httptest.ResponseRecorder can flush but not hijack
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/events", nil))
+
+	if !canFlush || !rec.Flushed {
+		t.Error("handler cannot flush through Middleware")
+	}
+
+	if canHijack {
+		t.Error("handler can hijack a ResponseWriter that cannot")
+	}
+}
+
+func TestMiddlewareHijack(t *testing.T) {
+	newTestLogger(t, "info", Config{})
+
+	srv := httptest.NewServer(Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, rw, err := w.(http.Hijacker).Hijack()
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		defer conn.Close()
+
+		_, _ = rw.WriteString("HTTP/1.1 200 OK\r\nContent-Length: 8\r\nConnection: close\r\n\r\nhijacked")
+		_ = rw.Flush()
+	})))
+	defer srv.Close()
+
+	resp, err := http.Get(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	if body, _ := io.ReadAll(resp.Body); string(body) != "hijacked" {
+		t.Errorf("body = %q, want hijacked", body)
+	}
+}
@@ -0,0 +1,44 @@
+package logger
+
+import (
+	"sync"
+
+	"go.uber.org/zap"
+)
+
+// namedLogger is a logger created by GetNamed.
+type namedLogger struct {
+	once   sync.Once
+	logger *zap.Logger
+	level  zap.AtomicLevel
+	output *output
+}
+
+// named holds the loggers created by GetNamed keyed by name.
+var named sync.Map
+
+// GetNamed is like Get but initializes a separate logger for each name,
+// with its own log file and level, independent of the one returned by
+// Get. Entries carry name as the logger name.
+func GetNamed(name, logPath, logLevel string, cfg ...Config) *zap.Logger {
+	v, _ := named.LoadOrStore(name, &namedLogger{level: zap.NewAtomicLevel()})
+	n := v.(*namedLogger)
+
+	n.once.Do(func() {
+		var l *zap.Logger
+		l, n.output, _ = build(logPath, logLevel, firstConfig(cfg), n.level)
+		n.logger = l.Named(name)
+	})
+
+	return n.logger
+}
+
+// resetNamed closes and discards every logger created by GetNamed.
+func resetNamed() {
+	named.Range(func(name, v any) bool {
+		_ = v.(*namedLogger).output.close()
+
+		named.Delete(name)
+		return true
+	})
+}
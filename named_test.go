@@ -0,0 +1,43 @@
+package logger
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestGetNamed(t *testing.T) {
+	resetForTest(t)
+	dir := t.TempDir()
+
+	db := GetNamed("db", filepath.Join(dir, "db.log"), "debug", Config{Quiet: true})
+	api := GetNamed("api", filepath.Join(dir, "api.log"), "warn", Config{Quiet: true})
+
+	if again := GetNamed("db", "", "info"); again != db {
+		t.Error("GetNamed returned a new logger for the same name")
+	}
+
+	db.Debug("db query")
+	api.Info("api request")
+	api.Warn("api slow")
+
+	read := func(name string) string {
+		b, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		return string(b)
+	}
+
+	if got := read("db.log"); !strings.Contains(got, "db query") || strings.Contains(got, "api slow") ||
+		!strings.Contains(got, `"logger":"db"`) {
+		t.Errorf("db.log = %q", got)
+	}
+
+	if got := read("api.log"); strings.Contains(got, "api request") || !strings.Contains(got, "api slow") ||
+		strings.Contains(got, "db query") {
+		t.Errorf("api.log = %q", got)
+	}
+}
@@ -0,0 +1,246 @@
+package logger
+
+import (
+	"io"
+	"net"
+	"os"
+	"sync"
+	"time"
+)
+
+const (
+	defaultNetworkBufferSize       = 1024
+	defaultNetworkTimeout          = 5 * time.Second
+	defaultNetworkFailureThreshold = 5
+	defaultNetworkCooldown         = 30 * time.Second
+)
+
+// NetworkConfig configures the network destination.
+type NetworkConfig struct {
+	// Network is "tcp" or "udp".
+	Network string
+
+	// Address of the collector, e.g. "logstash:5000".
+	Address string
+
+	// BufferSize is the number of entries queued while the collector is
+	// slow or down. Entries that do not fit are written to stderr rather
+	// than blocking the caller. Defaults to 1024.
+	BufferSize int
+
+	// Timeout bounds connecting and writing to the collector.
+	// Defaults to 5 seconds.
+	Timeout time.Duration
+
+	// FailureThreshold is the number of consecutive failures to connect
+	// or write after which the collector is given up on for Cooldown:
+	// entries go straight to stderr until a new attempt is made.
+	// Defaults to 5 failures and 30 seconds.
+	FailureThreshold int
+	Cooldown         time.Duration
+}
+
+// withDefaults returns cfg with the defaults applied.
+func (cfg NetworkConfig) withDefaults() NetworkConfig {
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = defaultNetworkTimeout
+	}
+
+	if cfg.BufferSize <= 0 {
+		cfg.BufferSize = defaultNetworkBufferSize
+	}
+
+	if cfg.FailureThreshold <= 0 {
+		cfg.FailureThreshold = defaultNetworkFailureThreshold
+	}
+
+	if cfg.Cooldown <= 0 {
+		cfg.Cooldown = defaultNetworkCooldown
+	}
+
+	return cfg
+}
+
+// connector opens connections to a log collector.
+type connector interface {
+	Connect() (io.WriteCloser, error)
+}
+
+// netConnector dials a collector over the network.
+type netConnector struct {
+	network string
+	address string
+	timeout time.Duration
+}
+
+func (c netConnector) Connect() (io.WriteCloser, error) {
+	return net.DialTimeout(c.network, c.address, c.timeout)
+}
+
+// netEntry is an encoded entry queued for the collector, or a request to
+// be notified once the entries queued before it have been sent.
+type netEntry struct {
+	b       []byte
+	flushed chan struct{}
+}
+
+// networkWriter is a zapcore.WriteSyncer sending entries to a collector
+// from a background goroutine. The connection is reopened after a
+// failure, and entries that cannot be sent are written to fallback.
+// After FailureThreshold consecutive failures, entries go to fallback
+// without trying the collector until Cooldown is over.
+type networkWriter struct {
+	connector connector
+	fallback  io.Writer
+	cfg       NetworkConfig
+
+	mu     sync.RWMutex
+	closed bool
+	queue  chan netEntry
+	done   chan struct{}
+
+	// conn, failures and openUntil are only used by the background
+	// goroutine.
+	conn      io.WriteCloser
+	failures  int
+	openUntil time.Time
+}
+
+// newNetworkWriter returns a networkWriter for the collector described
+// by cfg, falling back to stderr.
+func newNetworkWriter(cfg NetworkConfig) *networkWriter {
+	cfg = cfg.withDefaults()
+
+	c := netConnector{network: cfg.Network, address: cfg.Address, timeout: cfg.Timeout}
+	return startNetworkWriter(c, os.Stderr, cfg)
+}
+
+// startNetworkWriter returns a networkWriter connecting with c as
+// configured by cfg, whose defaults must be applied, and starts its
+// background goroutine.
+func startNetworkWriter(c connector, fallback io.Writer, cfg NetworkConfig) *networkWriter {
+	w := &networkWriter{
+		connector: c,
+		fallback:  fallback,
+		cfg:       cfg,
+		queue:     make(chan netEntry, cfg.BufferSize),
+		done:      make(chan struct{}),
+	}
+
+	go w.run()
+
+	return w
+}
+
+// Write queues p for the collector without blocking. It is written to
+// the fallback instead when the queue is full or w is closed.
+func (w *networkWriter) Write(p []byte) (int, error) {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+
+	if !w.closed {
+		// p is reused by zap once Write returns
+		select {
+		case w.queue <- netEntry{b: append([]byte(nil), p...)}:
+			return len(p), nil
+		default:
+		}
+	}
+
+	return w.fallback.Write(p)
+}
+
+// Sync waits for the entries queued so far to be sent.
+func (w *networkWriter) Sync() error {
+	w.mu.RLock()
+	if w.closed {
+		w.mu.RUnlock()
+		return nil
+	}
+
+	flushed := make(chan struct{})
+	w.queue <- netEntry{flushed: flushed}
+	w.mu.RUnlock()
+
+	<-flushed
+	return nil
+}
+
+// Close sends the queued entries and closes the connection. Entries
+// written afterwards go to the fallback.
+func (w *networkWriter) Close() error {
+	w.mu.Lock()
+	if w.closed {
+		w.mu.Unlock()
+		return nil
+	}
+
+	w.closed = true
+	close(w.queue)
+	w.mu.Unlock()
+
+	<-w.done
+
+	if w.conn != nil {
+		return w.conn.Close()
+	}
+
+	return nil
+}
+
+func (w *networkWriter) run() {
+	defer close(w.done)
+
+	for e := range w.queue {
+		if e.flushed != nil {
+			close(e.flushed)
+			continue
+		}
+
+		w.send(e.b)
+	}
+}
+
+// send writes b to the collector, connecting first if needed.
+func (w *networkWriter) send(b []byte) {
+	if time.Now().Before(w.openUntil) {
+		_, _ = w.fallback.Write(b)
+		return
+	}
+
+	if w.conn == nil {
+		conn, err := w.connector.Connect()
+		if err != nil {
+			w.fail(b)
+			return
+		}
+
+		w.conn = conn
+	}
+
+	if d, ok := w.conn.(interface{ SetWriteDeadline(time.Time) error }); ok {
+		_ = d.SetWriteDeadline(time.Now().Add(w.cfg.Timeout))
+	}
+
+	if _, err := w.conn.Write(b); err != nil {
+		_ = w.conn.Close()
+		w.conn = nil
+		w.fail(b)
+		return
+	}
+
+	w.failures = 0
+}
+
+// fail writes b, which could not be sent, to the fallback and gives up
+// on the collector for a while once it failed too many times in a row.
+func (w *networkWriter) fail(b []byte) {
+	_, _ = w.fallback.Write(b)
+
+	w.failures++
+	if w.failures >= w.cfg.FailureThreshold {
+		// A single failure after the cooldown opens the circuit again
+		w.failures = w.cfg.FailureThreshold - 1
+		w.openUntil = time.Now().Add(w.cfg.Cooldown)
+	}
+}
@@ -0,0 +1,83 @@
+package logger
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"net"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestNetworkSink(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+
+	l, _ := newTestLogger(t, "info", Config{
+		NetworkSink: &NetworkConfig{Network: "tcp", Address: ln.Addr().String()},
+	})
+
+	l.Info("shipped")
+	_ = Sync()
+
+	conn, err := ln.Accept()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	_ = conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	line, err := bufio.NewReader(conn).ReadString('\n')
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if entries := decodeEntries(t, line); entries[0]["msg"] != "shipped" {
+		t.Errorf("received %q", strings.TrimSpace(line))
+	}
+}
+
+// failingConnector is a connector failing after delay, as a dial to a
+// collector that is down does after its timeout.
+type failingConnector struct {
+	delay    time.Duration
+	attempts atomic.Int32
+}
+
+func (c *failingConnector) Connect() (io.WriteCloser, error) {
+	c.attempts.Add(1)
+	time.Sleep(c.delay)
+	return nil, errors.New("connection refused")
+}
+
+func TestNetworkWriterBreaker(t *testing.T) {
+	c := &failingConnector{delay: 20 * time.Millisecond}
+	fallback := &syncBuffer{}
+	w := startNetworkWriter(c, fallback, NetworkConfig{FailureThreshold: 3, Cooldown: time.Hour}.withDefaults())
+	defer w.Close()
+
+	start := time.Now()
+	for i := 0; i < 50; i++ {
+		if _, err := w.Write([]byte("entry\n")); err != nil {
+			t.Fatal(err)
+		}
+	}
+	_ = w.Sync()
+
+	if n := c.attempts.Load(); n != 3 {
+		t.Errorf("%d connection attempts, want 3 before the breaker opens", n)
+	}
+
+	if elapsed := time.Since(start); elapsed > 25*c.delay {
+		t.Errorf("50 writes took %v, want the breaker to stop the attempts", elapsed)
+	}
+
+	if n := strings.Count(fallback.String(), "entry\n"); n != 50 {
+		t.Errorf("%d of 50 entries written to the fallback, want all", n)
+	}
+}
@@ -0,0 +1,15 @@
+package logger
+
+import (
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+// NewObserved returns a Logger recording entries at level or above in
+// memory, and the recorded logs so tests can assert on their messages
+// and fields.
+func NewObserved(level zapcore.Level) (*zap.Logger, *observer.ObservedLogs) {
+	core, logs := observer.New(level)
+	return zap.New(core), logs
+}
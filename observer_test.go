@@ -0,0 +1,20 @@
+package logger
+
+import (
+	"errors"
+	"fmt"
+
+	"go.uber.org/zap"
+)
+
+func ExampleNewObserved() {
+	l, logs := NewObserved(zap.InfoLevel)
+
+	l.Debug("ignored")
+	l.Error("payment failed", zap.Error(errors.New("card declined")))
+
+	for _, entry := range logs.All() {
+		fmt.Println(entry.Level, entry.Message, entry.ContextMap()["error"])
+	}
+	// Output: error payment failed card declined
+}
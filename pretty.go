@@ -0,0 +1,41 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+
+	"go.uber.org/zap/buffer"
+	"go.uber.org/zap/zapcore"
+)
+
+var prettyPool = buffer.NewPool()
+
+// prettyEncoder is a zapcore.Encoder indenting the entries encoded by a
+// JSON encoder over several lines.
+type prettyEncoder struct {
+	zapcore.Encoder
+	lineEnding string
+}
+
+func (e prettyEncoder) Clone() zapcore.Encoder {
+	return prettyEncoder{Encoder: e.Encoder.Clone(), lineEnding: e.lineEnding}
+}
+
+func (e prettyEncoder) EncodeEntry(ent zapcore.Entry, fields []zapcore.Field) (*buffer.Buffer, error) {
+	line, err := e.Encoder.EncodeEntry(ent, fields)
+	if err != nil {
+		return nil, err
+	}
+	defer line.Free()
+
+	var indented bytes.Buffer
+	if err := json.Indent(&indented, bytes.TrimSuffix(line.Bytes(), []byte(e.lineEnding)), "", "  "); err != nil {
+		return nil, err
+	}
+
+	buf := prettyPool.Get()
+	_, _ = buf.Write(indented.Bytes())
+	buf.AppendString(e.lineEnding)
+
+	return buf, nil
+}
@@ -0,0 +1,26 @@
+package logger
+
+import (
+	"strings"
+	"testing"
+
+	"go.uber.org/zap"
+)
+
+func TestPrettyJSON(t *testing.T) {
+	t.Setenv("APP_ENV", "both")
+	stdout := captureStdout(t)
+	l, file := newTestLogger(t, "info", Config{PrettyJSON: true})
+
+	l.Info("order", zap.Any("order", map[string]interface{}{"id": 7, "items": []string{"book"}}))
+	_ = Sync()
+
+	if got := stdout(); !strings.Contains(got, "\n  \"order\": {\n    \"id\": 7,\n") {
+		t.Errorf("console = %q, want the nested field indented", got)
+	}
+
+	entries := decodeEntries(t, file.String())
+	if len(entries) != 1 || strings.Count(file.String(), "\n") != 1 {
+		t.Errorf("file = %q, want a single line entry", file.String())
+	}
+}
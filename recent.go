@@ -0,0 +1,127 @@
+package logger
+
+import (
+	"bytes"
+	"net/http"
+	"sync"
+	"sync/atomic"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// recent holds the last error entries once RecentErrors is called.
+var recent atomic.Pointer[errorRing]
+
+// RecentErrors starts keeping the last n entries logged at ErrorLevel or
+// above in memory and returns a handler serving them as a JSON array,
+// oldest first, e.g. for a /debug/errors endpoint. Calling it again
+// starts over with a new buffer.
+func RecentErrors(n int) http.Handler {
+	if n <= 0 {
+		n = 1
+	}
+
+	r := &errorRing{entries: make([][]byte, n)}
+	recent.Store(r)
+
+	return r
+}
+
+// errorRing is a fixed size buffer of encoded entries overwriting the
+// oldest entry when full.
+type errorRing struct {
+	mu      sync.Mutex
+	entries [][]byte
+	next    int
+	full    bool
+}
+
+func (r *errorRing) add(entry []byte) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.entries[r.next] = entry
+	r.next = (r.next + 1) % len(r.entries)
+	if r.next == 0 {
+		r.full = true
+	}
+}
+
+// snapshot returns the entries, oldest first.
+func (r *errorRing) snapshot() [][]byte {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if !r.full {
+		return append([][]byte(nil), r.entries[:r.next]...)
+	}
+
+	return append(append([][]byte(nil), r.entries[r.next:]...), r.entries[:r.next]...)
+}
+
+func (r *errorRing) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	_, _ = w.Write([]byte("["))
+	_, _ = w.Write(bytes.Join(r.snapshot(), []byte(",")))
+	_, _ = w.Write([]byte("]"))
+}
+
+// recentCore is a zapcore.Core adding the entries at ErrorLevel and
+// above to the buffer of RecentErrors, if any, encoded as JSON. The
+// fields added by With are only encoded along with such an entry, so
+// that they cost nothing while RecentErrors is unused.
+type recentCore struct {
+	enc    zapcore.Encoder
+	fields []zapcore.Field
+}
+
+// newRecentCore returns the recentCore encoding entries as configured
+// by c.
+func newRecentCore(c Config) zapcore.Core {
+	return &recentCore{enc: newEncoder(c, FormatJSON, false)}
+}
+
+func (c *recentCore) Enabled(l zapcore.Level) bool {
+	return l >= zapcore.ErrorLevel && recent.Load() != nil
+}
+
+func (c *recentCore) With(fields []zapcore.Field) zapcore.Core {
+	return &recentCore{
+		enc:    c.enc,
+		fields: append(append([]zapcore.Field(nil), c.fields...), fields...),
+	}
+}
+
+func (c *recentCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+
+	return ce
+}
+
+func (c *recentCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	r := recent.Load()
+	if r == nil {
+		return nil
+	}
+
+	enc := c.enc.Clone()
+	for _, f := range c.fields {
+		f.AddTo(enc)
+	}
+
+	buf, err := enc.EncodeEntry(ent, fields)
+	if err != nil {
+		return err
+	}
+	defer buf.Free()
+
+	r.add(bytes.TrimRight(append([]byte(nil), buf.Bytes()...), "\r\n"))
+	return nil
+}
+
+func (c *recentCore) Sync() error {
+	return nil
+}
@@ -0,0 +1,73 @@
+package logger
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestRecentErrors(t *testing.T) {
+	l, _ := newTestLogger(t, "info", Config{})
+	handler := RecentErrors(3)
+
+	for i := 1; i <= 5; i++ {
+		l.Error(fmt.Sprintf("error %d", i))
+		l.Info("routine")
+	}
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/debug/errors", nil))
+
+	var entries []map[string]interface{}
+	if err := json.Unmarshal(rec.Body.Bytes(), &entries); err != nil {
+		t.Fatalf("invalid response %q: %v", rec.Body, err)
+	}
+
+	var got []interface{}
+	for _, e := range entries {
+		got = append(got, e["msg"])
+	}
+
+	if want := "[error 3 error 4 error 5]"; fmt.Sprint(got) != want {
+		t.Errorf("messages = %v, want %s", got, want)
+	}
+}
+
+// countingMarshaler counts the times it is encoded.
+type countingMarshaler struct {
+	calls int
+}
+
+func (m *countingMarshaler) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	m.calls++
+	enc.AddString("name", "db")
+	return nil
+}
+
+func TestRecentCoreWithEncodesOnWrite(t *testing.T) {
+	recent.Store(nil)
+	t.Cleanup(func() { recent.Store(nil) })
+
+	m := &countingMarshaler{}
+	core := newRecentCore(Config{}).With([]zapcore.Field{zap.Object("component", m)})
+	if m.calls != 0 {
+		t.Fatalf("With encoded the fields %d times, want none until an entry is written", m.calls)
+	}
+
+	handler := RecentErrors(1)
+	if ce := core.Check(zapcore.Entry{Level: zapcore.ErrorLevel, Message: "failure"}, nil); ce != nil {
+		ce.Write()
+	}
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/debug/errors", nil))
+	if !strings.Contains(rec.Body.String(), `"component":{"name":"db"}`) || m.calls != 1 {
+		t.Errorf("got %s after %d encodings, want the fields encoded once with the entry", rec.Body, m.calls)
+	}
+}
@@ -0,0 +1,65 @@
+package logger
+
+import (
+	"context"
+	"runtime"
+	"runtime/debug"
+	"strings"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// RecoverAndLog recovers from a panic and logs it at error level through
+// FromCtx(ctx) with the recovered value as panic and the stack of the
+// panicking goroutine as stack. It must be deferred directly:
+//
+//	defer logger.RecoverAndLog(ctx)
+func RecoverAndLog(ctx context.Context) {
+	if r := recover(); r != nil {
+		logPanic(ctx, r)
+	}
+}
+
+// RecoverLogAndRepanic is like RecoverAndLog but panics again with the
+// recovered value once it is logged, e.g. to let the process crash.
+func RecoverLogAndRepanic(ctx context.Context) {
+	if r := recover(); r != nil {
+		logPanic(ctx, r)
+		panic(r)
+	}
+}
+
+// logPanic logs the recovered value r, from the deferred function of
+// the caller, with the function that panicked as caller.
+func logPanic(ctx context.Context, r interface{}) {
+	ce := FromCtx(ctx).Check(zap.ErrorLevel, "recovered from panic")
+	if ce == nil {
+		return
+	}
+
+	if ce.Caller.Defined {
+		ce.Caller = panicCaller()
+	}
+
+	ce.Write(zap.Any("panic", r), zap.ByteString("stack", debug.Stack()))
+}
+
+// panicCaller returns the frame that panicked, the first one below the
+// deferred function calling logPanic outside of the runtime.
+func panicCaller() zapcore.EntryCaller {
+	// runtime.Callers, panicCaller, logPanic and the deferred function
+	pcs := make([]uintptr, 16)
+	frames := runtime.CallersFrames(pcs[:runtime.Callers(4, pcs)])
+
+	for {
+		frame, more := frames.Next()
+		if !strings.HasPrefix(frame.Function, "runtime.") {
+			return zapcore.NewEntryCaller(frame.PC, frame.File, frame.Line, frame.PC != 0)
+		}
+
+		if !more {
+			return zapcore.EntryCaller{}
+		}
+	}
+}
@@ -0,0 +1,59 @@
+package logger
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+// panicking panics with "boom" guarded by guard.
+func panicking(ctx context.Context, guard func(context.Context)) {
+	defer guard(ctx)
+
+	var m map[string]int
+	m["boom"]++
+}
+
+func TestRecoverAndLog(t *testing.T) {
+	l, buf := newTestLogger(t, "info", Config{AddCaller: true})
+
+	panicking(WithCtx(context.Background(), l), RecoverAndLog)
+
+	entries := decodeEntries(t, buf.String())
+	if len(entries) != 1 {
+		t.Fatalf("got %d entries, want 1", len(entries))
+	}
+
+	e := entries[0]
+	if e["level"] != "error" || e["msg"] != "recovered from panic" {
+		t.Errorf("got %v, want the error entry", e)
+	}
+
+	if got, _ := e["panic"].(string); !strings.Contains(got, "nil map") {
+		t.Errorf("panic = %v, want the recovered value", e["panic"])
+	}
+
+	if got, _ := e["stack"].(string); !strings.Contains(got, "logger.panicking(") {
+		t.Errorf("stack = %q, want the panicking function", got)
+	}
+
+	if got, _ := e["caller"].(string); !strings.Contains(got, "recover_test.go:") {
+		t.Errorf("caller = %v, want the panicking function", e["caller"])
+	}
+}
+
+func TestRecoverLogAndRepanic(t *testing.T) {
+	l, buf := newTestLogger(t, "info", Config{})
+
+	defer func() {
+		if recover() == nil {
+			t.Error("the panic was not raised again")
+		}
+
+		if !strings.Contains(buf.String(), "recovered from panic") {
+			t.Error("the panic was not logged")
+		}
+	}()
+
+	panicking(WithCtx(context.Background(), l), RecoverLogAndRepanic)
+}
@@ -0,0 +1,85 @@
+package logger
+
+import (
+	"regexp"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// redactedValue replaces the value of redacted fields.
+const redactedValue = "***"
+
+// Redactor masks the value of sensitive fields, such as passwords or
+// tokens, before entries are written. Only top-level fields are
+// inspected.
+type Redactor struct {
+	// Keys are the keys of the fields to mask, matched exactly.
+	Keys []string
+
+	// Patterns mask the fields whose key matches any of them.
+	Patterns []*regexp.Regexp
+}
+
+// redacts reports whether the field with key must be masked.
+func (r *Redactor) redacts(key string) bool {
+	for _, k := range r.Keys {
+		if k == key {
+			return true
+		}
+	}
+
+	for _, p := range r.Patterns {
+		if p.MatchString(key) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// redact returns fields with the values of sensitive fields masked.
+// fields is copied rather than modified if any needs masking.
+func (r *Redactor) redact(fields []zapcore.Field) []zapcore.Field {
+	var redacted []zapcore.Field
+	for i, f := range fields {
+		if !r.redacts(f.Key) {
+			continue
+		}
+
+		if redacted == nil {
+			redacted = append([]zapcore.Field(nil), fields...)
+		}
+
+		redacted[i] = zap.String(f.Key, redactedValue)
+	}
+
+	if redacted == nil {
+		return fields
+	}
+
+	return redacted
+}
+
+// redactCore is a zapcore.Core masking sensitive fields before they
+// reach the wrapped core.
+type redactCore struct {
+	zapcore.Core
+	r *Redactor
+}
+
+func (c *redactCore) With(fields []zapcore.Field) zapcore.Core {
+	return &redactCore{Core: c.Core.With(c.r.redact(fields)), r: c.r}
+}
+
+func (c *redactCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+
+	return ce
+}
+
+func (c *redactCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	return c.Core.Write(ent, c.r.redact(fields))
+}
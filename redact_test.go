@@ -0,0 +1,29 @@
+package logger
+
+import (
+	"regexp"
+	"testing"
+
+	"go.uber.org/zap"
+)
+
+func TestRedact(t *testing.T) {
+	l, buf := newTestLogger(t, "info", Config{Redact: &Redactor{
+		Keys:     []string{"password"},
+		Patterns: []*regexp.Regexp{regexp.MustCompile(`(?i)token$`)},
+	}})
+
+	l.With(zap.String("apiToken", "t0k3n")).Info("login",
+		zap.String("username", "alice"),
+		zap.String("password", "hunter2"),
+	)
+
+	entry := decodeEntries(t, buf.String())[0]
+	if entry["password"] != redactedValue || entry["apiToken"] != redactedValue {
+		t.Errorf("got %v, want password and apiToken masked", entry)
+	}
+
+	if entry["username"] != "alice" {
+		t.Errorf("username = %v, want alice", entry["username"])
+	}
+}
@@ -0,0 +1,56 @@
+package logger
+
+import (
+	"bytes"
+	"errors"
+	"io/fs"
+	"os"
+)
+
+// repairPartialLine truncates the last line of the file at path if it
+// does not end with a newline, as left by a crash in the middle of a
+// write. A missing file is not an error.
+func repairPartialLine(path string) error {
+	f, err := os.OpenFile(path, os.O_RDWR, 0)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+
+	// Look for the last newline from the end, one chunk at a time
+	end := info.Size()
+	buf := make([]byte, 4096)
+	for pos := end; pos > 0; {
+		n := int64(len(buf))
+		if pos < n {
+			n = pos
+		}
+
+		pos -= n
+		if _, err := f.ReadAt(buf[:n], pos); err != nil {
+			return err
+		}
+
+		if i := bytes.LastIndexByte(buf[:n], '\n'); i >= 0 {
+			if last := pos + int64(i) + 1; last < end {
+				return f.Truncate(last)
+			}
+
+			return nil
+		}
+	}
+
+	// Not a single complete line
+	if end > 0 {
+		return f.Truncate(0)
+	}
+
+	return nil
+}
@@ -0,0 +1,62 @@
+package logger
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestRepairPartialLine(t *testing.T) {
+	tests := []struct {
+		name, content, want string
+	}{
+		{"truncated last line", "{\"msg\":\"a\"}\n{\"msg\":", "{\"msg\":\"a\"}\n"},
+		{"complete", "{\"msg\":\"a\"}\n", "{\"msg\":\"a\"}\n"},
+		{"single partial line", "{\"ms", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "app.log")
+			if err := os.WriteFile(path, []byte(tt.content), 0o644); err != nil {
+				t.Fatal(err)
+			}
+
+			if err := repairPartialLine(path); err != nil {
+				t.Fatal(err)
+			}
+
+			if b, _ := os.ReadFile(path); string(b) != tt.want {
+				t.Errorf("file = %q, want %q", b, tt.want)
+			}
+		})
+	}
+}
+
+func TestRepairPartialLineOnGet(t *testing.T) {
+	resetForTest(t)
+
+	path := filepath.Join(t.TempDir(), "app.log")
+	if err := os.WriteFile(path, []byte("{\"msg\":\"before crash\"}\n{\"msg\":\"cut"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	Get(path, "info", Config{RepairPartialLine: true, Quiet: true}).Info("after restart")
+	_ = Sync()
+
+	b, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	entries := decodeEntries(t, string(b))
+	if len(entries) != 2 || entries[1]["msg"] != "after restart" {
+		t.Errorf("got %v, want the complete entries", entries)
+	}
+}
+
+func TestRepairPartialLineMissingFile(t *testing.T) {
+	if err := repairPartialLine(filepath.Join(t.TempDir(), "missing.log")); err != nil {
+		t.Errorf("repairPartialLine() = %v, want nil", err)
+	}
+}
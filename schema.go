@@ -0,0 +1,129 @@
+package logger
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// SchemaConfig is the contract the fields of every entry must follow.
+type SchemaConfig struct {
+	// Fields maps the allowed keys to their type, e.g. zapcore.StringType
+	// for zap.String and zapcore.Int64Type for zap.Int.
+	// zapcore.UnknownType allows any type.
+	Fields map[string]zapcore.FieldType
+
+	// Panic panics on a violation, e.g. in development and CI, instead of
+	// logging a warning.
+	Panic bool
+}
+
+// check returns why a field with key and typ breaks the contract, or an
+// empty string. The type is not checked if typ is zapcore.UnknownType.
+func (s *SchemaConfig) check(key string, typ zapcore.FieldType) string {
+	want, ok := s.Fields[key]
+	switch {
+	case !ok:
+		return "unexpected key"
+	case want != zapcore.UnknownType && typ != zapcore.UnknownType && want != typ:
+		return "unexpected type"
+	}
+
+	return ""
+}
+
+// inlineKeys returns the sorted keys the inline field f, e.g. a field
+// returned by LogError, adds to an entry.
+func inlineKeys(f zapcore.Field) []string {
+	enc := zapcore.NewMapObjectEncoder()
+	if m, ok := f.Interface.(zapcore.ObjectMarshaler); ok {
+		_ = m.MarshalLogObject(enc)
+	}
+
+	keys := make([]string, 0, len(enc.Fields))
+	for key := range enc.Fields {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
+
+// schemaCore is a zapcore.Core reporting the fields that break a
+// SchemaConfig before they are written.
+type schemaCore struct {
+	zapcore.Core
+	schema *SchemaConfig
+}
+
+func (c *schemaCore) With(fields []zapcore.Field) zapcore.Core {
+	c.validate(fields)
+	return &schemaCore{Core: c.Core.With(fields), schema: c.schema}
+}
+
+// Check lets the wrapped core check ent and validates the fields of
+// the entries it accepts in a core of its own.
+func (c *schemaCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	ce = c.Core.Check(ent, ce)
+	if ce == nil {
+		return nil
+	}
+
+	return ce.AddCore(ent, schemaValidator{c})
+}
+
+func (c *schemaCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	c.validate(fields)
+	return c.Core.Write(ent, fields)
+}
+
+// validate reports the fields breaking the schema. Inline fields are
+// checked by the keys they add, whose types are not checked.
+func (c *schemaCore) validate(fields []zapcore.Field) {
+	for _, f := range fields {
+		switch f.Type {
+		case zapcore.SkipType:
+		case zapcore.InlineMarshalerType:
+			for _, key := range inlineKeys(f) {
+				c.report(key, c.schema.check(key, zapcore.UnknownType))
+			}
+		default:
+			c.report(f.Key, c.schema.check(f.Key, f.Type))
+		}
+	}
+}
+
+// report reports the field with key breaking the schema for reason, if
+// any.
+func (c *schemaCore) report(key, reason string) {
+	if reason == "" {
+		return
+	}
+
+	if c.schema.Panic {
+		panic(fmt.Sprintf("logger: field %q breaks the log schema: %s", key, reason))
+	}
+
+	ent := zapcore.Entry{Level: zapcore.WarnLevel, Time: time.Now(), Message: "log schema violation"}
+	if ce := c.Core.Check(ent, nil); ce != nil {
+		ce.Write(zap.String("field", key), zap.String("reason", reason))
+	}
+}
+
+// schemaValidator is the zapcore.Core validating the fields of the
+// entries checked by a schemaCore without writing them.
+type schemaValidator struct {
+	*schemaCore
+}
+
+func (v schemaValidator) Write(_ zapcore.Entry, fields []zapcore.Field) error {
+	v.validate(fields)
+	return nil
+}
+
+func (v schemaValidator) Sync() error {
+	return nil
+}
@@ -0,0 +1,51 @@
+package logger
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestSchema(t *testing.T) {
+	schema := &SchemaConfig{Fields: map[string]zapcore.FieldType{
+		"error": zapcore.StringType,
+		"user":  zapcore.StringType,
+	}}
+	l, buf := newTestLogger(t, "info", Config{Schema: schema})
+
+	l.Info("allowed", zap.String("user", "u1"), LogError(errors.New("boom")), LogError(nil))
+	l.Info("extra", zap.Int("attempt", 2))
+	l.Info("typed", zap.Int("user", 1))
+
+	var violations []string
+	for _, e := range decodeEntries(t, buf.String()) {
+		if e["msg"] == "log schema violation" {
+			violations = append(violations, e["field"].(string)+": "+e["reason"].(string))
+		}
+	}
+
+	want := []string{"attempt: unexpected key", "user: unexpected type"}
+	if len(violations) != len(want) || violations[0] != want[0] || violations[1] != want[1] {
+		t.Errorf("violations = %q, want %q", violations, want)
+	}
+}
+
+func TestSchemaPanic(t *testing.T) {
+	schema := &SchemaConfig{Fields: map[string]zapcore.FieldType{"error": zapcore.StringType}, Panic: true}
+	l, _ := newTestLogger(t, "info", Config{Schema: schema})
+
+	// The keys LogError adds are allowed
+	l.Info("allowed", LogError(errors.New("boom")))
+
+	defer func() {
+		if recover() == nil {
+			t.Error("a disallowed field did not panic")
+		}
+	}()
+
+	// The error_chain key of a wrapped error is not
+	l.Info("extra", LogError(fmt.Errorf("wrap: %w", errors.New("boom"))))
+}
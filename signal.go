@@ -0,0 +1,66 @@
+package logger
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// shutdownSignals are the signals InstallSignalSync flushes logs on.
+var shutdownSignals = []os.Signal{syscall.SIGTERM, os.Interrupt}
+
+// raiseSignal is raise, replaceable in tests.
+var raiseSignal = raise
+
+// InstallSignalSync flushes the logger with Sync when the process
+// receives SIGTERM or SIGINT, so that buffered entries are not lost when
+// e.g. Kubernetes stops a pod. Once flushed the signal is raised again
+// with its handling reset, so the process terminates as it would have
+// without InstallSignalSync. Cancelling ctx uninstalls the handler.
+//
+// It should be called once from main. Applications handling these
+// signals themselves should call Sync during their own shutdown instead.
+func InstallSignalSync(ctx context.Context) {
+	ch := make(chan os.Signal, 1)
+	signal.Notify(ch, shutdownSignals...)
+
+	go syncOnSignal(ctx, ch)
+}
+
+// syncOnSignal waits for a signal on ch, then flushes the logger and
+// raises the signal again. It returns early if ctx is cancelled.
+func syncOnSignal(ctx context.Context, ch chan os.Signal) {
+	select {
+	case <-ctx.Done():
+		signal.Stop(ch)
+	case sig := <-ch:
+		_ = Sync()
+		signal.Stop(ch)
+		raiseSignal(sig)
+	}
+}
+
+// raise sends sig to the current process, exiting if that is not
+// supported by the platform.
+func raise(sig os.Signal) {
+	p, err := os.FindProcess(os.Getpid())
+	if err != nil || p.Signal(sig) != nil {
+		os.Exit(1)
+	}
+}
+
+// InstallReopenOnSIGHUP rotates the log file when the process receives
+// SIGHUP, so that it can be coordinated with an external logrotate: once
+// logrotate has renamed the file, SIGHUP makes the logger reopen a new
+// one at the configured path.
+func InstallReopenOnSIGHUP() {
+	ch := make(chan os.Signal, 1)
+	signal.Notify(ch, syscall.SIGHUP)
+
+	go func() {
+		for range ch {
+			_ = Rotate()
+		}
+	}()
+}
@@ -0,0 +1,100 @@
+package logger
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"runtime"
+	"sync/atomic"
+	"syscall"
+	"testing"
+	"time"
+)
+
+// syncCounter is a log destination counting the calls to Sync.
+type syncCounter struct {
+	syncBuffer
+	syncs atomic.Int32
+}
+
+func (w *syncCounter) Sync() error {
+	w.syncs.Add(1)
+	return nil
+}
+
+func TestSyncOnSignal(t *testing.T) {
+	resetForTest(t)
+
+	w := &syncCounter{}
+	Get("", "info", Config{Writer: w, Quiet: true})
+
+	raised := make(chan os.Signal, 1)
+	raiseSignal = func(sig os.Signal) { raised <- sig }
+	t.Cleanup(func() { raiseSignal = raise })
+
+	ch := make(chan os.Signal, 1)
+	ch <- syscall.SIGTERM
+	syncOnSignal(context.Background(), ch)
+
+	if w.syncs.Load() == 0 {
+		t.Error("logger not synced on signal")
+	}
+
+	if sig := <-raised; sig != syscall.SIGTERM {
+		t.Errorf("raised %v, want SIGTERM", sig)
+	}
+}
+
+func TestSyncOnSignalCancelled(t *testing.T) {
+	resetForTest(t)
+
+	w := &syncCounter{}
+	Get("", "info", Config{Writer: w, Quiet: true})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	syncOnSignal(ctx, make(chan os.Signal, 1))
+
+	if w.syncs.Load() != 0 {
+		t.Error("logger synced without a signal")
+	}
+}
+
+func TestReopenOnSIGHUP(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("SIGHUP cannot be sent on Windows")
+	}
+
+	resetForTest(t)
+
+	// Nothing is left to write to the directory once the file is reopened
+	compress := false
+	path := filepath.Join(t.TempDir(), "app.log")
+	Get(path, "info", Config{Compress: &compress, Quiet: true}).Info("before")
+
+	// As logrotate does
+	if err := os.Rename(path, path+".1"); err != nil {
+		t.Fatal(err)
+	}
+
+	InstallReopenOnSIGHUP()
+	t.Cleanup(func() { signal.Reset(syscall.SIGHUP) })
+
+	p, err := os.FindProcess(os.Getpid())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := p.Signal(syscall.SIGHUP); err != nil {
+		t.Fatal(err)
+	}
+
+	for deadline := time.Now().Add(5 * time.Second); ; time.Sleep(10 * time.Millisecond) {
+		if _, err := os.Stat(path); err == nil {
+			break
+		} else if time.Now().After(deadline) {
+			t.Fatal("log file not reopened after SIGHUP")
+		}
+	}
+}
@@ -0,0 +1,14 @@
+//go:build !plan9
+
+package logger
+
+import (
+	"errors"
+	"syscall"
+)
+
+// isBenignSyncError reports whether err is the error returned when
+// syncing a terminal or pipe, which cannot be synced.
+func isBenignSyncError(err error) bool {
+	return errors.Is(err, syscall.EINVAL) || errors.Is(err, syscall.ENOTTY)
+}
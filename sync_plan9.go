@@ -0,0 +1,15 @@
+//go:build plan9
+
+package logger
+
+import (
+	"errors"
+	"syscall"
+)
+
+// isBenignSyncError reports whether err is the error returned when
+// syncing a console or pipe, which cannot be synced. Plan 9 has no
+// ENOTTY.
+func isBenignSyncError(err error) bool {
+	return errors.Is(err, syscall.EINVAL)
+}
@@ -0,0 +1,92 @@
+package logger
+
+import (
+	"strings"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// SyslogConfig configures the syslog destination.
+type SyslogConfig struct {
+	// Network and Address of the syslog daemon as accepted by syslog.Dial.
+	// When both are empty the local daemon is used.
+	Network string
+	Address string
+
+	// Facility is the syslog facility name, such as "daemon" or "local0".
+	// Defaults to "user".
+	Facility string
+
+	// Tag is prepended to every message. Defaults to the program name.
+	Tag string
+}
+
+// syslogWriter is the subset of *syslog.Writer used by syslogCore.
+type syslogWriter interface {
+	Debug(m string) error
+	Info(m string) error
+	Warning(m string) error
+	Err(m string) error
+	Crit(m string) error
+	Emerg(m string) error
+	Close() error
+}
+
+// syslogCore is a zapcore.Core writing each encoded entry to a syslog
+// writer with the severity matching its level.
+type syslogCore struct {
+	zapcore.LevelEnabler
+	enc zapcore.Encoder
+	w   syslogWriter
+}
+
+func newSyslogCore(enc zapcore.Encoder, w syslogWriter, enab zapcore.LevelEnabler) zapcore.Core {
+	return &syslogCore{LevelEnabler: enab, enc: enc, w: w}
+}
+
+func (c *syslogCore) With(fields []zapcore.Field) zapcore.Core {
+	clone := &syslogCore{LevelEnabler: c.LevelEnabler, enc: c.enc.Clone(), w: c.w}
+	for _, f := range fields {
+		f.AddTo(clone.enc)
+	}
+
+	return clone
+}
+
+func (c *syslogCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+
+	return ce
+}
+
+func (c *syslogCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	buf, err := c.enc.EncodeEntry(ent, fields)
+	if err != nil {
+		return err
+	}
+
+	msg := strings.TrimRight(buf.String(), "\r\n")
+	buf.Free()
+
+	switch ent.Level {
+	case zapcore.DebugLevel:
+		return c.w.Debug(msg)
+	case zapcore.InfoLevel:
+		return c.w.Info(msg)
+	case zapcore.WarnLevel:
+		return c.w.Warning(msg)
+	case zapcore.ErrorLevel:
+		return c.w.Err(msg)
+	case zapcore.FatalLevel:
+		return c.w.Emerg(msg)
+	default:
+		// DPanic and Panic
+		return c.w.Crit(msg)
+	}
+}
+
+func (c *syslogCore) Sync() error {
+	return nil
+}
@@ -0,0 +1,10 @@
+//go:build windows || plan9
+
+package logger
+
+import "errors"
+
+// dialSyslog always fails as log/syslog is not available on this platform.
+func dialSyslog(SyslogConfig) (syslogWriter, error) {
+	return nil, errors.New("syslog is not supported on this platform")
+}
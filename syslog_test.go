@@ -0,0 +1,55 @@
+package logger
+
+import (
+	"strings"
+	"testing"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// fakeSyslog is a syslogWriter recording the severity of every message.
+type fakeSyslog struct {
+	severities []string
+	messages   []string
+}
+
+func (w *fakeSyslog) record(severity, m string) error {
+	w.severities = append(w.severities, severity)
+	w.messages = append(w.messages, m)
+	return nil
+}
+
+func (w *fakeSyslog) Debug(m string) error   { return w.record("debug", m) }
+func (w *fakeSyslog) Info(m string) error    { return w.record("info", m) }
+func (w *fakeSyslog) Warning(m string) error { return w.record("warning", m) }
+func (w *fakeSyslog) Err(m string) error     { return w.record("err", m) }
+func (w *fakeSyslog) Crit(m string) error    { return w.record("crit", m) }
+func (w *fakeSyslog) Emerg(m string) error   { return w.record("emerg", m) }
+func (w *fakeSyslog) Close() error           { return nil }
+
+func TestSyslogCoreSeverity(t *testing.T) {
+	w := &fakeSyslog{}
+	core := newSyslogCore(newEncoder(Config{}, FormatJSON, false), w, zap.DebugLevel)
+
+	levels := []zapcore.Level{
+		zap.DebugLevel, zap.InfoLevel, zap.WarnLevel, zap.ErrorLevel,
+		zap.DPanicLevel, zap.PanicLevel, zap.FatalLevel,
+	}
+	for _, l := range levels {
+		if err := core.Write(zapcore.Entry{Level: l, Message: l.String()}, nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	want := []string{"debug", "info", "warning", "err", "crit", "crit", "emerg"}
+	if got := strings.Join(w.severities, ","); got != strings.Join(want, ",") {
+		t.Errorf("severities = %s, want %s", got, strings.Join(want, ","))
+	}
+
+	for _, m := range w.messages {
+		if strings.HasSuffix(m, "\n") {
+			t.Errorf("message %q ends with a newline", m)
+		}
+	}
+}
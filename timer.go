@@ -0,0 +1,22 @@
+package logger
+
+import (
+	"context"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// StartTimer starts timing and returns a function logging msg at info
+// level through FromCtx(ctx), with the milliseconds elapsed since
+// StartTimer as the duration_ms field. It may be called several times,
+// e.g. once per step, each entry carrying the total elapsed so far.
+func StartTimer(ctx context.Context) func(msg string, fields ...zap.Field) {
+	start := time.Now()
+	l := FromCtx(ctx).WithOptions(zap.AddCallerSkip(1))
+
+	return func(msg string, fields ...zap.Field) {
+		elapsed := float64(time.Since(start)) / float64(time.Millisecond)
+		l.Info(msg, append(fields, zap.Float64("duration_ms", elapsed))...)
+	}
+}
@@ -0,0 +1,20 @@
+package logger
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestStartTimer(t *testing.T) {
+	_, buf := newTestLogger(t, "info", Config{})
+
+	done := StartTimer(context.Background())
+	time.Sleep(2 * time.Millisecond)
+	done("step done")
+
+	entry := decodeEntries(t, buf.String())[0]
+	if ms, _ := entry["duration_ms"].(float64); ms <= 0 {
+		t.Errorf("duration_ms = %v, want a positive number", entry["duration_ms"])
+	}
+}
@@ -0,0 +1,44 @@
+package logger
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// LogTraceContext returns trace_id and span_id fields for the
+// OpenTelemetry span stored in ctx, or no fields if there is none.
+func LogTraceContext(ctx context.Context) []zapcore.Field {
+	sc := trace.SpanContextFromContext(ctx)
+	if !sc.IsValid() {
+		return []zapcore.Field{}
+	}
+
+	return []zapcore.Field{
+		zap.String("trace_id", sc.TraceID().String()),
+		zap.String("span_id", sc.SpanID().String()),
+	}
+}
+
+// FromCtxWithTrace is like FromCtx but the returned Logger also carries
+// the trace_id and span_id of the OpenTelemetry span stored in ctx.
+func FromCtxWithTrace(ctx context.Context) *zap.Logger {
+	l := FromCtx(ctx)
+	if fields := LogTraceContext(ctx); len(fields) > 0 {
+		return l.With(fields...)
+	}
+
+	return l
+}
+
+// escalateSampled returns l logging debug entries when ctx carries a
+// sampled OpenTelemetry span and Config.DebugSampledTraces is set.
+func escalateSampled(ctx context.Context, l *zap.Logger) *zap.Logger {
+	if defaultOutput == nil || !defaultOutput.debugSampled || !trace.SpanContextFromContext(ctx).IsSampled() {
+		return l
+	}
+
+	return l.With(forceLevel(zapcore.DebugLevel))
+}
@@ -0,0 +1,79 @@
+package logger
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+// spanContext returns ctx carrying a fabricated span, sampled or not.
+func spanContext(ctx context.Context, sampled bool) context.Context {
+	cfg := trace.SpanContextConfig{
+		TraceID: trace.TraceID{0x0a, 0x0b},
+		SpanID:  trace.SpanID{0x0c},
+	}
+	if sampled {
+		cfg.TraceFlags = trace.FlagsSampled
+	}
+
+	return trace.ContextWithSpanContext(ctx, trace.NewSpanContext(cfg))
+}
+
+func TestFromCtxWithTrace(t *testing.T) {
+	l, buf := newTestLogger(t, "info", Config{})
+
+	FromCtxWithTrace(spanContext(WithCtx(context.Background(), l), false)).Info("traced")
+	FromCtxWithTrace(context.Background()).Info("untraced")
+
+	entries := decodeEntries(t, buf.String())
+	if len(entries) != 2 {
+		t.Fatalf("got %d entries, want 2", len(entries))
+	}
+
+	if got := entries[0]["trace_id"]; got != "0a0b0000000000000000000000000000" {
+		t.Errorf("trace_id = %v", got)
+	}
+
+	if got := entries[0]["span_id"]; got != "0c00000000000000" {
+		t.Errorf("span_id = %v", got)
+	}
+
+	if _, ok := entries[1]["trace_id"]; ok {
+		t.Error("trace_id logged without a span")
+	}
+}
+
+func TestDebugSampledTraces(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  Config
+		want []string
+	}{
+		{"enabled", Config{DebugSampledTraces: true}, []string{"sampled"}},
+		{"with dedup", Config{DebugSampledTraces: true, Dedup: &DedupConfig{Window: time.Hour}}, []string{"sampled"}},
+		{"disabled", Config{}, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l, buf := newTestLogger(t, "info", tt.cfg)
+			ctx := WithCtx(context.Background(), l)
+
+			FromCtx(spanContext(ctx, true)).Debug("sampled")
+			FromCtx(spanContext(ctx, false)).Debug("unsampled")
+			_ = Sync()
+
+			var got []string
+			for _, e := range decodeEntries(t, buf.String()) {
+				got = append(got, e["msg"].(string))
+			}
+
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}